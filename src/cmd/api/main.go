@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/configs"
+	"golang_marketplace/src/internal/core/auth"
+	authservice "golang_marketplace/src/internal/core/auth/service"
+	"golang_marketplace/src/internal/core/notification"
+	"golang_marketplace/src/internal/core/product"
+	"golang_marketplace/src/internal/core/product/service"
+	"golang_marketplace/src/internal/core/user"
+	userdomain "golang_marketplace/src/internal/core/user/domain"
+	userservice "golang_marketplace/src/internal/core/user/service"
+	"golang_marketplace/src/internal/platform/cache"
+	"golang_marketplace/src/internal/platform/database"
+	"golang_marketplace/src/internal/platform/health"
+	"golang_marketplace/src/internal/platform/logging"
+	"golang_marketplace/src/internal/platform/mailer"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	cfg, err := configs.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(cfg, logger, os.Args[2:])
+	} else {
+		err = run(cfg, logger)
+	}
+
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(cfg *configs.Config, logger *slog.Logger) error {
+	db, err := database.Connect(cfg.Database, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := database.Close(db); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+
+	if err := ensureMigrated(db); err != nil {
+		return err
+	}
+
+	appCache, err := cache.New(cfg.Cache, cfg.Redis)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := appCache.Close(); err != nil {
+			logger.Error("failed to close cache", "error", err)
+		}
+	}()
+
+	productModule := product.NewModule(db, appCache, service.CacheOptions{
+		Product: cache.LoaderOptions{
+			SoftTTL:     cfg.Cache.ProductTTL,
+			HardTTL:     cfg.Cache.ProductStaleTTL,
+			NegativeTTL: cfg.Cache.NotFoundTTL,
+		},
+		List: cache.LoaderOptions{
+			SoftTTL: cfg.Cache.ListTTL,
+			HardTTL: cfg.Cache.ListStaleTTL,
+		},
+	}, logger)
+
+	appMailer, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return err
+	}
+
+	// denylists, lockouts and mail limits must not make room for cached pages
+	if pinner, ok := appCache.(cache.Pinner); ok {
+		pinner.Pin(auth.CacheKeyPrefix)
+	}
+	authModule := auth.NewModule(db, appCache, appMailer, authservice.TokenOptions{
+		Secret:          []byte(cfg.Auth.JWTSecret),
+		Issuer:          cfg.Auth.Issuer,
+		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+	}, authservice.AccountOptions{
+		AppURL:           cfg.Auth.AppURL,
+		VerificationTTL:  cfg.Auth.VerificationTTL,
+		PasswordResetTTL: cfg.Auth.PasswordResetTTL,
+	}, authservice.LockoutOptions{
+		MaxAttempts:   cfg.Auth.LoginMaxAttempts,
+		IPMaxAttempts: cfg.Auth.LoginIPMaxAttempts,
+		Window:        cfg.Auth.LoginAttemptWindow,
+		Duration:      cfg.Auth.LockoutDuration,
+		MaxDuration:   cfg.Auth.MaxLockoutDuration,
+	}, logger)
+
+	notificationModule := notification.NewModule(db, appMailer, logger)
+
+	exportKey := []byte(cfg.Export.SigningKey)
+	if len(exportKey) == 0 {
+		// a label of its own keeps export links and tokens from standing in
+		// for each other
+		exportKey, err = hkdf.Key(sha256.New, []byte(cfg.Auth.JWTSecret), nil, "data export links", sha256.Size)
+		if err != nil {
+			return fmt.Errorf("failed to derive export signing key: %w", err)
+		}
+	}
+	// recently viewed lists are sorted sets, which only the redis cache keeps
+	views, _ := appCache.(userdomain.RecentList)
+	if views == nil {
+		logger.Info("recently viewed products are off without the redis cache driver")
+	}
+	userModule := user.NewModule(db, productModule.Service, views, notificationModule.Service, userservice.ExportOptions{
+		Dir:        cfg.Export.Dir,
+		TTL:        cfg.Export.TTL,
+		SigningKey: exportKey,
+		BaseURL:    strings.TrimRight(cfg.Auth.AppURL, "/") + "/api/v1",
+	}, userservice.DeletionOptions{
+		GracePeriod: cfg.User.DeletionGracePeriod,
+	}, userservice.RecentlyViewedOptions{
+		Limit: cfg.User.RecentlyViewedLimit,
+		TTL:   cfg.User.RecentlyViewedTTL,
+	}, logger)
+	userModule.Service.RegisterExportSource("auth_events", authModule.ExportUserEvents)
+	userModule.Service.RegisterDeletionHook(authModule.RevokeDeletedUserAccess)
+	userModule.Service.RegisterAnonymizeHook(authModule.AnonymizeDeletedUser)
+	userModule.Service.RegisterAnonymizeHook(productModule.Service.AnonymizeSeller)
+	userModule.Service.RegisterExportSource("notifications", notificationModule.Service.ExportUserData)
+	userModule.Service.RegisterAnonymizeHook(notificationModule.Service.DeleteUserData)
+
+	if cfg.LogLevel != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	// without this gin believes X-Forwarded-For from anyone, and client
+	// addresses feed login lockouts and rate limits
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("failed to set trusted proxies: %w", err)
+	}
+	router.Use(gin.Recovery(), logging.Middleware(logger), authModule.ClientInfo())
+
+	healthHandler := health.NewHandler()
+	healthHandler.AddCheck("postgres", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	healthHandler.AddCheck(cfg.Cache.Driver, appCache.Ping)
+	healthHandler.RegisterRoutes(router)
+	api := router.Group("/api/v1")
+	authModule.RegisterRoutes(api)
+	productModule.RegisterRoutes(api, authModule.Authenticate(), authModule.OptionalAuthenticate(), userModule.TrackProductViews())
+	userModule.RegisterRoutes(api, authModule.Authenticate())
+	notificationModule.RegisterRoutes(api, authModule.Authenticate())
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var sweeper sync.WaitGroup
+	sweeper.Add(1)
+	go func() {
+		defer sweeper.Done()
+		userModule.RunDeletionSweeper(ctx, time.Hour)
+	}()
+	// background work uses the database and the cache, which the earlier
+	// defers close, so it has to finish first
+	defer func() {
+		stop()
+		done := make(chan struct{})
+		go func() {
+			sweeper.Wait()
+			// exports notify when they are ready, so they go before deliveries
+			userModule.Service.WaitForExports()
+			notificationModule.Service.WaitForDeliveries()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(cfg.Server.ShutdownTimeout):
+			logger.Warn("stopped waiting for background work")
+		}
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down server")
+
+	healthHandler.SetDraining()
+	time.Sleep(cfg.Server.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// stop accepting new connections and wait for in-flight requests
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+
+	return nil
+}
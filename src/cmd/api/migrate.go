@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/configs"
+	"golang_marketplace/src/internal/platform/database"
+	"golang_marketplace/src/internal/platform/migration"
+	"golang_marketplace/src/migrations"
+	"gorm.io/gorm"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: api migrate <up|down [steps]|status>"
+
+func runMigrate(cfg *configs.Config, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Connect(cfg.Database, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := database.Close(db); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrations applied", "count", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrations rolled back", "count", rolledBack)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+func newMigrator(db *gorm.DB) (*migration.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	return migration.New(sqlDB, migrations.FS)
+}
+
+// ensureMigrated refuses to start the API against a schema that is behind
+// the migrations compiled into the binary.
+func ensureMigrated(db *gorm.DB) error {
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migration(s), starting with %03d_%s; run `api migrate up` first",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+
+	return nil
+}
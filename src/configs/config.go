@@ -1,60 +1,581 @@
 package configs
 
-import "os"
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/netip"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
 
+// defaultConfigFile is read when CONFIG_FILE is not set, if it exists.
+const defaultConfigFile = "config.yaml"
+
+// Config is built in layers: defaults, then the YAML file named by
+// CONFIG_FILE, then environment variables. Every field with an env tag can
+// also be read from a file named by the same variable with a _FILE suffix,
+// which is how Docker secrets are mounted.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	LogLevel string
+	Server    ServerConfig   `yaml:"server"`
+	Database  DatabaseConfig `yaml:"database"`
+	Redis     RedisConfig    `yaml:"redis"`
+	Cache     CacheConfig    `yaml:"cache"`
+	Auth      AuthConfig     `yaml:"auth"`
+	Mail      MailConfig     `yaml:"mail"`
+	Export    ExportConfig   `yaml:"export"`
+	User      UserConfig     `yaml:"user"`
+	LogLevel  string         `yaml:"log_level" env:"LOG_LEVEL"`
+	LogFormat string         `yaml:"log_format" env:"LOG_FORMAT"`
 }
 
 type ServerConfig struct {
-	Port string
+	Port            string        `yaml:"port" env:"SERVER_PORT"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// DrainDelay is how long /readyz reports draining before the server
+	// stops accepting connections, giving load balancers time to notice.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+	// TrustedProxies lists the addresses and CIDR ranges whose
+	// X-Forwarded-For headers are believed when taking the client address.
+	// By default none are, and the connection's address is used.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host         string `yaml:"host" env:"DB_HOST"`
+	Port         string `yaml:"port" env:"DB_PORT"`
+	User         string `yaml:"user" env:"DB_USER"`
+	Password     string `yaml:"password" env:"DB_PASSWORD"`
+	DBName       string `yaml:"name" env:"DB_NAME"`
+	SSLMode      string `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+	MaxOpenConns int    `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns int    `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// ReplicaDSNs lists read replicas; reads are spread across them and
+	// writes always go to the primary.
+	ReplicaDSNs []string `yaml:"replica_dsns" env:"DB_REPLICA_DSNS"`
+
+	// StatementTimeout cancels any single statement that runs longer.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// SlowQueryThreshold logs statements that take longer as warnings.
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+	LogLevel           string        `yaml:"log_level" env:"DB_LOG_LEVEL"`
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
+	Host     string `yaml:"host" env:"REDIS_HOST"`
+	Port     string `yaml:"port" env:"REDIS_PORT"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+type CacheConfig struct {
+	Driver string `yaml:"driver" env:"CACHE_DRIVER"`
+	// MaxEntries bounds the memory driver's cached pages. Auth state is
+	// kept apart and never evicted to make room for them.
+	MaxEntries int `yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+
+	// ProductTTL is how long a product is served as fresh and
+	// ProductStaleTTL how long it may be served stale while refreshing.
+	ProductTTL      time.Duration `yaml:"product_ttl" env:"CACHE_PRODUCT_TTL"`
+	ProductStaleTTL time.Duration `yaml:"product_stale_ttl" env:"CACHE_PRODUCT_STALE_TTL"`
+	NotFoundTTL     time.Duration `yaml:"not_found_ttl" env:"CACHE_NOT_FOUND_TTL"`
+	ListTTL         time.Duration `yaml:"list_ttl" env:"CACHE_LIST_TTL"`
+	ListStaleTTL    time.Duration `yaml:"list_stale_ttl" env:"CACHE_LIST_STALE_TTL"`
+}
+
+type AuthConfig struct {
+	JWTSecret       string        `yaml:"jwt_secret" env:"AUTH_JWT_SECRET"`
+	Issuer          string        `yaml:"issuer" env:"AUTH_ISSUER"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"AUTH_ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"AUTH_REFRESH_TOKEN_TTL"`
+
+	// AppURL is the base of the links put in verification and password
+	// reset emails.
+	AppURL           string        `yaml:"app_url" env:"AUTH_APP_URL"`
+	VerificationTTL  time.Duration `yaml:"verification_ttl" env:"AUTH_VERIFICATION_TTL"`
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl" env:"AUTH_PASSWORD_RESET_TTL"`
+
+	// Failed logins counted within LoginAttemptWindow lock the account, or
+	// the client address, once they reach the limit. Each further lockout
+	// within a day doubles LockoutDuration, up to MaxLockoutDuration.
+	LoginMaxAttempts   int           `yaml:"login_max_attempts" env:"AUTH_LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts int           `yaml:"login_ip_max_attempts" env:"AUTH_LOGIN_IP_MAX_ATTEMPTS"`
+	LoginAttemptWindow time.Duration `yaml:"login_attempt_window" env:"AUTH_LOGIN_ATTEMPT_WINDOW"`
+	LockoutDuration    time.Duration `yaml:"lockout_duration" env:"AUTH_LOCKOUT_DURATION"`
+	MaxLockoutDuration time.Duration `yaml:"max_lockout_duration" env:"AUTH_MAX_LOCKOUT_DURATION"`
+}
+
+type MailConfig struct {
+	// Driver is smtp, or file to write messages to OutboxDir instead of
+	// sending them.
+	Driver    string `yaml:"driver" env:"MAIL_DRIVER"`
+	From      string `yaml:"from" env:"MAIL_FROM"`
+	OutboxDir string `yaml:"outbox_dir" env:"MAIL_OUTBOX_DIR"`
+
+	SMTPHost     string `yaml:"smtp_host" env:"MAIL_SMTP_HOST"`
+	SMTPPort     string `yaml:"smtp_port" env:"MAIL_SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"MAIL_SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"MAIL_SMTP_PASSWORD"`
+}
+
+type ExportConfig struct {
+	// Dir holds the archives of personal data exports until they expire,
+	// TTL after they are ready.
+	Dir string        `yaml:"dir" env:"EXPORT_DIR"`
+	TTL time.Duration `yaml:"ttl" env:"EXPORT_TTL"`
+	// SigningKey signs the download links. When it is empty, a key is
+	// derived from the JWT secret under a label of its own.
+	SigningKey string `yaml:"signing_key" env:"EXPORT_SIGNING_KEY"`
+}
+
+type UserConfig struct {
+	// DeletionGracePeriod is how long a deleted account can be restored
+	// before its personal data is scrubbed.
+	DeletionGracePeriod time.Duration `yaml:"deletion_grace_period" env:"USER_DELETION_GRACE_PERIOD"`
+	// RecentlyViewedLimit caps how many products each recently viewed list
+	// keeps.
+	RecentlyViewedLimit int `yaml:"recently_viewed_limit" env:"USER_RECENTLY_VIEWED_LIMIT"`
+	// RecentlyViewedTTL is how long a list is kept after its last view.
+	RecentlyViewedTTL time.Duration `yaml:"recently_viewed_ttl" env:"USER_RECENTLY_VIEWED_TTL"`
+}
+
+// ValidationError lists every problem found while loading the config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
 }
 
-func Load() *Config {
+func defaults() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:            "8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "postgres"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Host:         "localhost",
+			Port:         "5432",
+			User:         "postgres",
+			Password:     "postgres",
+			DBName:       "postgres",
+			SSLMode:      "disable",
+			MaxOpenConns: 25,
+			MaxIdleConns: 10,
+
+			ConnMaxLifetime:    30 * time.Minute,
+			ConnMaxIdleTime:    5 * time.Minute,
+			StatementTimeout:   10 * time.Second,
+			SlowQueryThreshold: 200 * time.Millisecond,
+			LogLevel:           "warn",
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       0,
+			Host: "localhost",
+			Port: "6379",
+		},
+		Cache: CacheConfig{
+			Driver:          "redis",
+			MaxEntries:      10000,
+			ProductTTL:      5 * time.Minute,
+			ProductStaleTTL: 15 * time.Minute,
+			NotFoundTTL:     30 * time.Second,
+			ListTTL:         1 * time.Minute,
+			ListStaleTTL:    5 * time.Minute,
 		},
-		LogLevel: getEnv("LOG_LEVEL", "info"),
+		Auth: AuthConfig{
+			Issuer:          "golang_marketplace",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+
+			AppURL:           "http://localhost:8080",
+			VerificationTTL:  24 * time.Hour,
+			PasswordResetTTL: time.Hour,
+
+			LoginMaxAttempts:   5,
+			LoginIPMaxAttempts: 50,
+			LoginAttemptWindow: 15 * time.Minute,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+		},
+		Mail: MailConfig{
+			Driver:    "file",
+			From:      "no-reply@localhost",
+			OutboxDir: "outbox",
+			SMTPPort:  "587",
+		},
+		Export: ExportConfig{
+			Dir: "exports",
+			TTL: 48 * time.Hour,
+		},
+		User: UserConfig{
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			RecentlyViewedLimit: 50,
+			RecentlyViewedTTL:   90 * 24 * time.Hour,
+		},
+		LogLevel:  "info",
+		LogFormat: "json",
+	}
+}
+
+func Load() (*Config, error) {
+	cfg := defaults()
+
+	if err := loadFile(cfg); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	applyEnv(reflect.ValueOf(cfg).Elem(), &problems)
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+func loadFile(cfg *Config) error {
+	path := os.Getenv("CONFIG_FILE")
+	required := path != ""
+	if !required {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv overrides every field tagged with env from the environment,
+// recording a problem for each value that does not parse.
+func applyEnv(v reflect.Value, problems *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		key := t.Field(i).Tag.Get("env")
+
+		if key == "" {
+			if field.Kind() == reflect.Struct {
+				applyEnv(field, problems)
+			}
+			continue
+		}
+
+		value, ok, err := lookupEnv(key)
+		if err != nil {
+			*problems = append(*problems, err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setField(field, value); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", key, err))
+		}
 	}
 }
 
-func getEnv(key, defaultValue string) string {
+func lookupEnv(key string) (string, bool, error) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		return value, true, nil
+	}
+
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s_FILE: %v", key, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", field.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !validPort(c.Server.Port) {
+		add("server.port: must be a port number, got %q", c.Server.Port)
+	}
+	if c.Server.ReadTimeout < 0 {
+		add("server.read_timeout: must not be negative")
+	}
+	if c.Server.WriteTimeout < 0 {
+		add("server.write_timeout: must not be negative")
+	}
+	if c.Server.IdleTimeout < 0 {
+		add("server.idle_timeout: must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout: must be positive")
+	}
+	if c.Server.DrainDelay < 0 {
+		add("server.drain_delay: must not be negative")
+	}
+	for i, proxy := range c.Server.TrustedProxies {
+		if !validAddressOrRange(proxy) {
+			add("server.trusted_proxies[%d]: must be an IP address or CIDR range, got %q", i, proxy)
+		}
+	}
+
+	if c.Database.Host == "" {
+		add("database.host: is required")
+	}
+	if !validPort(c.Database.Port) {
+		add("database.port: must be a port number, got %q", c.Database.Port)
+	}
+	if c.Database.User == "" {
+		add("database.user: is required")
+	}
+	if c.Database.DBName == "" {
+		add("database.name: is required")
+	}
+	if !oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full") {
+		add("database.ssl_mode: unknown mode %q", c.Database.SSLMode)
+	}
+	if c.Database.MaxOpenConns < 0 {
+		add("database.max_open_conns: must not be negative")
+	}
+	if c.Database.MaxIdleConns < 0 {
+		add("database.max_idle_conns: must not be negative")
+	} else if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns: must not exceed max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		add("database.conn_max_lifetime: must not be negative")
+	}
+	if c.Database.ConnMaxIdleTime < 0 {
+		add("database.conn_max_idle_time: must not be negative")
+	}
+	for i, dsn := range c.Database.ReplicaDSNs {
+		if strings.TrimSpace(dsn) == "" {
+			add("database.replica_dsns[%d]: must not be empty", i)
+		}
+	}
+	if c.Database.StatementTimeout < 0 {
+		add("database.statement_timeout: must not be negative")
+	}
+	if c.Database.SlowQueryThreshold < 0 {
+		add("database.slow_query_threshold: must not be negative")
+	}
+	if !oneOf(c.Database.LogLevel, "silent", "error", "warn", "info") {
+		add("database.log_level: must be one of silent, error, warn, info, got %q", c.Database.LogLevel)
+	}
+
+	if !oneOf(c.Cache.Driver, "redis", "memory") {
+		add("cache.driver: must be redis or memory, got %q", c.Cache.Driver)
+	}
+	if c.Cache.Driver == "redis" {
+		if c.Redis.Host == "" {
+			add("redis.host: is required")
+		}
+		if !validPort(c.Redis.Port) {
+			add("redis.port: must be a port number, got %q", c.Redis.Port)
+		}
+		if c.Redis.DB < 0 || c.Redis.DB > 15 {
+			add("redis.db: must be between 0 and 15")
+		}
+	}
+	if c.Cache.Driver == "memory" && c.Cache.MaxEntries <= 0 {
+		add("cache.max_entries: must be positive")
+	}
+	if c.Cache.ProductTTL <= 0 {
+		add("cache.product_ttl: must be positive")
+	}
+	if c.Cache.ProductStaleTTL < c.Cache.ProductTTL {
+		add("cache.product_stale_ttl: must not be shorter than product_ttl")
+	}
+	if c.Cache.NotFoundTTL < 0 {
+		add("cache.not_found_ttl: must not be negative")
+	}
+	if c.Cache.ListTTL <= 0 {
+		add("cache.list_ttl: must be positive")
+	}
+	if c.Cache.ListStaleTTL < c.Cache.ListTTL {
+		add("cache.list_stale_ttl: must not be shorter than list_ttl")
+	}
+
+	if len(c.Auth.JWTSecret) < 32 {
+		add("auth.jwt_secret: must be at least 32 characters")
+	}
+	if c.Auth.Issuer == "" {
+		add("auth.issuer: is required")
+	}
+	if c.Auth.AccessTokenTTL <= 0 {
+		add("auth.access_token_ttl: must be positive")
+	}
+	if c.Auth.RefreshTokenTTL <= c.Auth.AccessTokenTTL {
+		add("auth.refresh_token_ttl: must be longer than access_token_ttl")
+	}
+	if !strings.HasPrefix(c.Auth.AppURL, "http://") && !strings.HasPrefix(c.Auth.AppURL, "https://") {
+		add("auth.app_url: must be an http or https URL, got %q", c.Auth.AppURL)
+	}
+	if c.Auth.VerificationTTL <= 0 {
+		add("auth.verification_ttl: must be positive")
+	}
+	if c.Auth.PasswordResetTTL <= 0 {
+		add("auth.password_reset_ttl: must be positive")
+	}
+	if c.Auth.LoginMaxAttempts <= 0 {
+		add("auth.login_max_attempts: must be positive")
+	}
+	if c.Auth.LoginIPMaxAttempts < c.Auth.LoginMaxAttempts {
+		add("auth.login_ip_max_attempts: must be at least login_max_attempts")
+	}
+	if c.Auth.LoginAttemptWindow <= 0 {
+		add("auth.login_attempt_window: must be positive")
+	}
+	if c.Auth.LockoutDuration <= 0 {
+		add("auth.lockout_duration: must be positive")
+	}
+	if c.Auth.MaxLockoutDuration < c.Auth.LockoutDuration {
+		add("auth.max_lockout_duration: must be at least lockout_duration")
+	}
+
+	switch c.Mail.Driver {
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			add("mail.smtp_host: is required")
+		}
+		if !validPort(c.Mail.SMTPPort) {
+			add("mail.smtp_port: must be a port number, got %q", c.Mail.SMTPPort)
+		}
+	case "file":
+		if c.Mail.OutboxDir == "" {
+			add("mail.outbox_dir: is required")
+		}
+	default:
+		add("mail.driver: must be smtp or file, got %q", c.Mail.Driver)
+	}
+	if !strings.Contains(c.Mail.From, "@") {
+		add("mail.from: must be an email address, got %q", c.Mail.From)
+	}
+
+	if c.Export.Dir == "" {
+		add("export.dir: is required")
+	}
+	if c.Export.TTL <= 0 {
+		add("export.ttl: must be positive")
+	}
+	if c.Export.SigningKey != "" && len(c.Export.SigningKey) < 32 {
+		add("export.signing_key: must be at least 32 characters")
+	}
+	if c.Export.SigningKey != "" && c.Export.SigningKey == c.Auth.JWTSecret {
+		add("export.signing_key: must differ from auth.jwt_secret")
+	}
+
+	if c.User.DeletionGracePeriod < 0 {
+		add("user.deletion_grace_period: must not be negative")
+	}
+	if c.User.RecentlyViewedLimit < 1 {
+		add("user.recently_viewed_limit: must be positive")
+	}
+	if c.User.RecentlyViewedTTL <= 0 {
+		add("user.recently_viewed_ttl: must be positive")
+	}
+
+	if !oneOf(c.LogLevel, "debug", "info", "warn", "error") {
+		add("log_level: must be one of debug, info, warn, error, got %q", c.LogLevel)
+	}
+	if !oneOf(c.LogFormat, "json", "text") {
+		add("log_format: must be json or text, got %q", c.LogFormat)
+	}
+
+	return problems
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+func validAddressOrRange(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
 	}
-	return defaultValue
+	return false
 }
@@ -0,0 +1,161 @@
+package configs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// inTempDir runs the test from an empty directory, so that a config.yaml in
+// the working tree is not picked up.
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		env   map[string]string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != "8080" || cfg.Cache.Driver != "redis" || cfg.Server.TrustedProxies != nil {
+					t.Errorf("unexpected defaults: %+v", cfg.Server)
+				}
+			},
+		},
+		{
+			name: "file over defaults",
+			yaml: "server:\n  port: \"9000\"\n  read_timeout: 3s\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != "9000" || cfg.Server.ReadTimeout != 3*time.Second {
+					t.Errorf("file not applied: %+v", cfg.Server)
+				}
+				if cfg.Server.WriteTimeout != 15*time.Second {
+					t.Errorf("unset field lost its default: %v", cfg.Server.WriteTimeout)
+				}
+			},
+		},
+		{
+			name: "environment over file",
+			yaml: "server:\n  port: \"9000\"\n",
+			env:  map[string]string{"SERVER_PORT": "9100"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != "9100" {
+					t.Errorf("port = %s, want 9100", cfg.Server.Port)
+				}
+			},
+		},
+		{
+			name: "lists from the environment",
+			env:  map[string]string{"SERVER_TRUSTED_PROXIES": "10.0.0.0/8, 192.168.1.1,"},
+			check: func(t *testing.T, cfg *Config) {
+				want := []string{"10.0.0.0/8", "192.168.1.1"}
+				if !reflect.DeepEqual(cfg.Server.TrustedProxies, want) {
+					t.Errorf("trusted proxies = %v, want %v", cfg.Server.TrustedProxies, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := inTempDir(t)
+			t.Setenv("AUTH_JWT_SECRET", testSecret)
+			if tt.yaml != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, dir, "config.yaml", tt.yaml))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadReadsSecretFiles(t *testing.T) {
+	dir := inTempDir(t)
+	t.Setenv("AUTH_JWT_SECRET_FILE", writeFile(t, dir, "jwt_secret", testSecret+"\n"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Auth.JWTSecret != testSecret {
+		t.Errorf("jwt secret = %q, want it without the trailing newline", cfg.Auth.JWTSecret)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	inTempDir(t)
+	t.Setenv("AUTH_JWT_SECRET", "short")
+	t.Setenv("SERVER_PORT", "http")
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "proxy.internal")
+
+	_, err := Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+
+	for _, want := range []string{"auth.jwt_secret", "server.port", "SERVER_READ_TIMEOUT", "server.trusted_proxies[0]"} {
+		found := false
+		for _, problem := range invalid.Problems {
+			if strings.Contains(problem, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no problem mentions %s: %v", want, invalid.Problems)
+		}
+	}
+}
+
+func TestLoadRejectsSharedExportKey(t *testing.T) {
+	inTempDir(t)
+	t.Setenv("AUTH_JWT_SECRET", testSecret)
+	t.Setenv("EXPORT_SIGNING_KEY", testSecret)
+
+	_, err := Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 1 || !strings.Contains(invalid.Problems[0], "export.signing_key") {
+		t.Errorf("err = %v, want export.signing_key reported", err)
+	}
+}
+
+func TestLoadRequiresNamedFile(t *testing.T) {
+	dir := inTempDir(t)
+	t.Setenv("AUTH_JWT_SECRET", testSecret)
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+
+	if _, err := Load(); err == nil {
+		t.Error("a missing CONFIG_FILE was ignored")
+	}
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "golang_marketplace/src/internal/core/auth/domain"
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type LockoutListResponse struct {
+	Lockouts []*domain.LockoutEvent `json:"lockouts"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	Limit    int                    `json:"limit"`
+}
+
+type AuthEventListResponse struct {
+	Events []*domain.AuthEvent `json:"events"`
+	Total  int64               `json:"total"`
+	Page   int                 `json:"page"`
+	Limit  int                 `json:"limit"`
+}
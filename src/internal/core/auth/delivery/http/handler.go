@@ -0,0 +1,926 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/delivery/dto"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/pkg/validator"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuthHandler struct {
+	service domain.AuthService
+	logger  *slog.Logger
+}
+
+func NewAuthHandler(service domain.AuthService, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Register godoc
+// @Summary Register a new user
+// @Description Create a customer account and return a token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body domain.RegisterInput true "Registration data"
+// @Success 201 {object} domain.UserWithToken
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req domain.RegisterInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.service.Register(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, "failed to register user", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchange email and password for a token pair, or for a challenge token when two-factor authentication is enabled
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body domain.LoginInput true "Credentials"
+// @Success 200 {object} domain.LoginResult
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Header 429 {integer} Retry-After "Seconds until login is unlocked"
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req domain.LoginInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		h.handleError(c, "failed to log in", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CompleteMFALogin godoc
+// @Summary Finish a two-factor login
+// @Description Exchange a challenge token and a TOTP or recovery code for a token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param challenge body domain.MFALoginInput true "Challenge token and code"
+// @Success 200 {object} domain.UserWithToken
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) CompleteMFALogin(c *gin.Context) {
+	var req domain.MFALoginInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.service.CompleteMFALogin(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, "failed to complete two-factor login", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body domain.RefreshInput true "Refresh token"
+// @Success 200 {object} domain.TokenPair
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req domain.RefreshInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tokens, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.handleError(c, "failed to refresh token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the current access token and the session of the given refresh token
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param token body domain.LogoutInput false "Refresh token of the session to end"
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.LogoutInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	if err := h.service.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		h.handleError(c, "failed to log out", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password and end every session of the user
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param password body domain.ChangePasswordInput true "Current and new password"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.ChangePasswordInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.service.ChangePassword(c.Request.Context(), claims.UserID, req); err != nil {
+		h.handleError(c, "failed to change password", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List the devices the caller is signed in on, most recently used first
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Session
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), claims)
+	if err != nil {
+		h.handleError(c, "failed to list sessions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Sign one of the caller's sessions out
+// @Tags auth
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid session ID"})
+		return
+	}
+
+	if err := h.service.RevokeSession(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to revoke session", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions godoc
+// @Summary Revoke all sessions
+// @Description Sign the caller out everywhere, including this session
+// @Tags auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	if err := h.service.RevokeAllSessions(c.Request.Context(), claims.UserID); err != nil {
+		h.handleError(c, "failed to revoke sessions", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestEmailVerification godoc
+// @Summary Resend the verification email
+// @Description Mail a new email verification link to the caller. Earlier links stop working.
+// @Tags auth
+// @Security BearerAuth
+// @Success 202
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/verify-email/request [post]
+func (h *AuthHandler) RequestEmailVerification(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	if err := h.service.RequestEmailVerification(c.Request.Context(), claims.UserID); err != nil {
+		h.handleError(c, "failed to request email verification", err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Consume an email verification token. Tokens issued before verification still say unverified; refresh them to pick up the change.
+// @Tags auth
+// @Accept json
+// @Param token body domain.VerifyEmailInput true "Verification token"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req domain.VerifyEmailInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err := h.service.VerifyEmail(c.Request.Context(), req)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		h.handleError(c, "failed to verify email", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset godoc
+// @Summary Request a password reset
+// @Description Mail a password reset link if the email is registered. The response is the same either way.
+// @Tags auth
+// @Accept json
+// @Param email body domain.PasswordResetRequestInput true "Account email"
+// @Success 202
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/password-reset/request [post]
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req domain.PasswordResetRequestInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req); err != nil {
+		h.handleError(c, "failed to request password reset", err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with a password reset token and end every session of the user
+// @Tags auth
+// @Accept json
+// @Param reset body domain.ResetPasswordInput true "Reset token and new password"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /auth/password-reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err := h.service.ResetPassword(c.Request.Context(), req)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		h.handleError(c, "failed to reset password", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EnrollMFA godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and its otpauth:// provisioning URI for a QR code. Sellers and admins only.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.MFASetup
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /auth/2fa/enroll [post]
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	setup, err := h.service.EnrollMFA(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to enroll in two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// ActivateMFA godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm a code from the authenticator app and receive recovery codes. They are only shown once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body domain.MFACodeInput true "TOTP code"
+// @Success 200 {object} domain.RecoveryCodes
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/activate [post]
+func (h *AuthHandler) ActivateMFA(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.MFACodeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := h.service.ActivateMFA(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to activate two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableMFA godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off, unless the caller's role requires it
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param confirmation body domain.DisableMFAInput true "Password and TOTP or recovery code"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.DisableMFAInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.service.DisableMFA(c.Request.Context(), claims.UserID, req); err != nil {
+		h.handleError(c, "failed to disable two-factor authentication", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes. The new codes are only shown once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body domain.MFACodeInput true "TOTP code"
+// @Success 200 {object} domain.RecoveryCodes
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Router /auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.MFACodeInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to regenerate recovery codes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// ListMFAPolicies godoc
+// @Summary List two-factor policies
+// @Description List which roles must use two-factor authentication
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.MFARolePolicy
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/2fa-policies [get]
+func (h *AuthHandler) ListMFAPolicies(c *gin.Context) {
+	policies, err := h.service.ListMFAPolicies(c.Request.Context())
+	if err != nil {
+		h.handleError(c, "failed to list two-factor policies", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// SetMFAPolicy godoc
+// @Summary Set a two-factor policy
+// @Description Require or stop requiring two-factor authentication for a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param role path string true "Role"
+// @Param policy body domain.SetMFAPolicyInput true "Policy"
+// @Success 200 {object} domain.MFARolePolicy
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/2fa-policies/{role} [put]
+func (h *AuthHandler) SetMFAPolicy(c *gin.Context) {
+	var req domain.SetMFAPolicyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	policy, err := h.service.SetMFAPolicy(c.Request.Context(), c.Param("role"), req)
+	if err != nil {
+		h.handleError(c, "failed to set two-factor policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// ListLockouts godoc
+// @Summary List login lockouts
+// @Description List recorded lockouts of accounts and client addresses, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "User ID"
+// @Param email query string false "Email"
+// @Param ip query string false "Client address"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.LockoutListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/lockouts [get]
+func (h *AuthHandler) ListLockouts(c *gin.Context) {
+	filter := domain.LockoutFilter{
+		Email: c.Query("email"),
+		IP:    c.Query("ip"),
+		Page:  1,
+		Limit: 50,
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if userID, err := strconv.ParseUint(userIDStr, 10, 64); err == nil {
+			id := uint(userID)
+			filter.UserID = &id
+		}
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			filter.Page = page
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+
+	events, total, err := h.service.ListLockouts(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "failed to list lockouts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LockoutListResponse{
+		Lockouts: events,
+		Total:    total,
+		Page:     filter.Page,
+		Limit:    filter.Limit,
+	})
+}
+
+// UnlockUser godoc
+// @Summary Unlock an account
+// @Description Lift a login lockout on a user's account before it expires
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.UnlockUser(c.Request.Context(), claims.UserID, uint(userID)); err != nil {
+		h.handleError(c, "failed to unlock user", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// TerminateUserSessions godoc
+// @Summary Terminate a user's sessions
+// @Description Sign a user out everywhere, invalidating their refresh and access tokens
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/sessions [delete]
+func (h *AuthHandler) TerminateUserSessions(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.TerminateUserSessions(c.Request.Context(), claims.UserID, uint(userID)); err != nil {
+		h.handleError(c, "failed to terminate sessions", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetUserRole godoc
+// @Summary Set a user's role
+// @Description Grant a user a role. Their access tokens are denied so the change applies on the next refresh.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param role body domain.SetRoleInput true "Role"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *AuthHandler) SetUserRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var req domain.SetRoleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.service.SetUserRole(c.Request.Context(), uint(userID), req)
+	if err != nil {
+		h.handleError(c, "failed to set user role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ListAuthEvents godoc
+// @Summary List auth events
+// @Description Query the authentication audit log, newest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "User ID"
+// @Param type query string false "Event type"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Latest time (exclusive), RFC 3339"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.AuthEventListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/auth-events [get]
+func (h *AuthHandler) ListAuthEvents(c *gin.Context) {
+	filter, err := parseAuthEventFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	events, total, err := h.service.ListAuthEvents(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "failed to list auth events", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthEventListResponse{
+		Events: events,
+		Total:  total,
+		Page:   filter.Page,
+		Limit:  filter.Limit,
+	})
+}
+
+// ExportAuthEvents godoc
+// @Summary Export auth events
+// @Description Stream matching audit log events as JSON lines, oldest first, for ingestion by a SIEM
+// @Tags admin
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param user_id query int false "User ID"
+// @Param type query string false "Event type"
+// @Param from query string false "Earliest time, RFC 3339"
+// @Param to query string false "Latest time (exclusive), RFC 3339"
+// @Success 200 {string} string "One JSON event per line"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /admin/auth-events/export [get]
+func (h *AuthHandler) ExportAuthEvents(c *gin.Context) {
+	filter, err := parseAuthEventFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="auth-events.jsonl"`)
+
+	encoder := json.NewEncoder(c.Writer)
+	err = h.service.ExportAuthEvents(c.Request.Context(), filter, func(event *domain.AuthEvent) error {
+		return encoder.Encode(event)
+	})
+	if err != nil {
+		if !c.Writer.Written() {
+			h.handleError(c, "failed to export auth events", err)
+			return
+		}
+		// the status is already sent, so all that is left is to stop
+		h.logger.ErrorContext(c.Request.Context(), "failed to export auth events", "error", err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a scoped API key for a seller integration. The key is only returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body domain.CreateAPIKeyInput true "Key name, scopes and optional expiry"
+// @Success 201 {object} domain.CreatedAPIKey
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /auth/api-keys [post]
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to create api key", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the caller's API keys, including revoked and expired ones
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.APIKey
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /auth/api-keys [get]
+func (h *AuthHandler) ListAPIKeys(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	keys, err := h.service.ListAPIKeys(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to list api keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke one of the caller's API keys
+// @Tags auth
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /auth/api-keys/{id} [delete]
+func (h *AuthHandler) RevokeAPIKey(c *gin.Context) {
+	claims, _ := domain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid API key ID"})
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to revoke api key", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseAuthEventFilter reads the filters shared by listing and exporting
+// auth events. Only listing pages.
+func parseAuthEventFilter(c *gin.Context) (domain.AuthEventFilter, error) {
+	filter := domain.AuthEventFilter{
+		Type:  c.Query("type"),
+		Page:  1,
+		Limit: 50,
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 64)
+		if err != nil {
+			return filter, errors.New("Invalid user ID")
+		}
+		id := uint(userID)
+		filter.UserID = &id
+	}
+
+	for name, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid %s: must be an RFC 3339 time", name)
+			}
+			*dest = &t
+		}
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			filter.Page = page
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+
+	return filter, nil
+}
+
+func (h *AuthHandler) handleError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, validator.ErrValidation), errors.Is(err, domain.ErrUnknownRole),
+		errors.Is(err, domain.ErrMFANotEnrolled):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrEmailTaken), errors.Is(err, domain.ErrAlreadyVerified),
+		errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrAPIKeyNotFound), errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrInvalidToken),
+		errors.Is(err, domain.ErrTokenReused), errors.Is(err, domain.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrMFARequired), errors.Is(err, domain.ErrMFANotAllowed):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrTooManyAttempts), errors.Is(err, domain.ErrTooManyRequests):
+		var locked *domain.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		}
+		c.JSON(http.StatusTooManyRequests, dto.ErrorResponse{Error: err.Error()})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), msg, "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubAuthService answers the public auth routes with a fixed error.
+type stubAuthService struct {
+	domain.AuthService
+	err error
+}
+
+func (s *stubAuthService) Login(context.Context, string, string) (*domain.LoginResult, error) {
+	return nil, s.err
+}
+
+func (s *stubAuthService) CompleteMFALogin(context.Context, domain.MFALoginInput) (*domain.UserWithToken, error) {
+	return nil, s.err
+}
+
+func (s *stubAuthService) RequestPasswordReset(context.Context, domain.PasswordResetRequestInput) error {
+	return s.err
+}
+
+func newTestRouter(service domain.AuthService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterRoutes(router.Group(""), service, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return router
+}
+
+func TestThrottledRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		err            error
+		wantCode       int
+		wantRetryAfter string
+	}{
+		{
+			name:     "password reset over its limit",
+			path:     "/auth/password-reset/request",
+			body:     `{"email":"jane@example.com"}`,
+			err:      domain.ErrTooManyRequests,
+			wantCode: http.StatusTooManyRequests,
+		},
+		{
+			name:     "password reset within its limit",
+			path:     "/auth/password-reset/request",
+			body:     `{"email":"jane@example.com"}`,
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:           "locked login",
+			path:           "/auth/login",
+			body:           `{"email":"jane@example.com","password":"secret-password"}`,
+			err:            &domain.LockedError{RetryAfter: 90*time.Second + time.Millisecond},
+			wantCode:       http.StatusTooManyRequests,
+			wantRetryAfter: "91",
+		},
+		{
+			name:           "locked second factor",
+			path:           "/auth/login/2fa",
+			body:           `{"challenge_token":"token","code":"123456"}`,
+			err:            &domain.LockedError{RetryAfter: time.Minute},
+			wantCode:       http.StatusTooManyRequests,
+			wantRetryAfter: "60",
+		},
+		{
+			name:     "wrong second factor",
+			path:     "/auth/login/2fa",
+			body:     `{"challenge_token":"token","code":"123456"}`,
+			err:      domain.ErrInvalidMFACode,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&stubAuthService{err: tt.err})
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
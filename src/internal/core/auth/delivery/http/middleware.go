@@ -0,0 +1,163 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/auth/delivery/dto"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/logging"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader can carry an API key instead of the Authorization header.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate rejects requests without a valid bearer token or API key and
+// stores the caller's claims in the request context for handlers and later
+// middleware.
+func Authenticate(service domain.AuthService, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := credentials(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "missing bearer token"})
+			return
+		}
+
+		claims, err := validate(c, service, token)
+		if errors.Is(err, domain.ErrInvalidToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "failed to validate token", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+			return
+		}
+
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuthenticate is Authenticate for public routes that behave
+// differently for known callers. Requests without valid credentials go
+// through anonymously instead of being rejected.
+func OptionalAuthenticate(service domain.AuthService, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := credentials(c); token != "" {
+			claims, err := validate(c, service, token)
+			if err == nil {
+				setClaims(c, claims)
+			} else if !errors.Is(err, domain.ErrInvalidToken) {
+				logger.WarnContext(c.Request.Context(), "failed to validate token", "error", err)
+			}
+		}
+		c.Next()
+	}
+}
+
+func credentials(c *gin.Context) string {
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || token == "" {
+		token = c.GetHeader(APIKeyHeader)
+	}
+	return token
+}
+
+func validate(c *gin.Context, service domain.AuthService, token string) (*domain.Claims, error) {
+	if strings.HasPrefix(token, domain.APIKeyPrefix) {
+		return service.ValidateAPIKey(c.Request.Context(), token)
+	}
+	return service.ValidateToken(c.Request.Context(), token)
+}
+
+func setClaims(c *gin.Context, claims *domain.Claims) {
+	ctx := logging.WithAttrs(c.Request.Context(), slog.Uint64("user_id", uint64(claims.UserID)))
+	c.Request = c.Request.WithContext(domain.ContextWithClaims(ctx, claims))
+}
+
+// ClientInfo stores the caller's address and user agent in the request
+// context for login throttling, session records and the audit log.
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client := domain.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+		c.Request = c.Request.WithContext(domain.ContextWithClient(c.Request.Context(), client))
+		c.Next()
+	}
+}
+
+// RequireRole only lets callers with one of roles through, and only once
+// they passed two-factor authentication if their role requires it. It must
+// run after Authenticate.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := domain.ClaimsFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if !claims.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: "insufficient permissions"})
+			return
+		}
+		if claims.MFAPending {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: domain.ErrMFARequired.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks callers whose email address is not verified,
+// for actions such as selling or checking out. It must run after
+// Authenticate.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := domain.ClaimsFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if !claims.EmailVerified {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: domain.ErrEmailNotVerified.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope limits API keys to routes within their scopes. Interactive
+// logins pass unchanged. It must run after Authenticate.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := domain.ClaimsFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if !claims.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: "api key lacks scope " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects API keys, for routes that manage the account
+// itself. It must run after Authenticate.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := domain.ClaimsFromContext(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if claims.APIKeyID != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, dto.ErrorResponse{Error: "api keys cannot be used here"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientInfoAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		remote  string
+		forward string
+		want    string
+	}{
+		{name: "no proxies trusted", remote: "203.0.113.7:5000", forward: "198.51.100.1", want: "203.0.113.7"},
+		{name: "request from a trusted proxy", proxies: []string{"10.0.0.0/8"}, remote: "10.1.2.3:5000", forward: "198.51.100.1", want: "198.51.100.1"},
+		{name: "request around the proxy", proxies: []string{"10.0.0.0/8"}, remote: "203.0.113.7:5000", forward: "198.51.100.1", want: "203.0.113.7"},
+		{name: "no forwarded header", proxies: []string{"10.0.0.0/8"}, remote: "10.1.2.3:5000", want: "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			router.Use(ClientInfo())
+			var got domain.ClientInfo
+			router.GET("/", func(c *gin.Context) {
+				got = domain.ClientFromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("User-Agent", "test-agent")
+			if tt.forward != "" {
+				req.Header.Set("X-Forwarded-For", tt.forward)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got.IP != tt.want {
+				t.Errorf("client address = %q, want %q", got.IP, tt.want)
+			}
+			if got.UserAgent != "test-agent" {
+				t.Errorf("user agent = %q", got.UserAgent)
+			}
+		})
+	}
+}
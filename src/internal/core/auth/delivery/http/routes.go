@@ -0,0 +1,62 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"log/slog"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, service domain.AuthService, logger *slog.Logger) {
+	handler := NewAuthHandler(service, logger)
+	authenticate := Authenticate(service, logger)
+	session := RequireSession()
+
+	auth := router.Group("/auth")
+	{
+		auth.POST("/register", handler.Register)
+		auth.POST("/login", handler.Login)
+		auth.POST("/login/2fa", handler.CompleteMFALogin)
+		auth.POST("/refresh", handler.Refresh)
+		auth.POST("/logout", authenticate, session, handler.Logout)
+		auth.PUT("/password", authenticate, session, handler.ChangePassword)
+		auth.POST("/verify-email/request", authenticate, session, handler.RequestEmailVerification)
+		auth.POST("/verify-email", handler.VerifyEmail)
+		auth.POST("/password-reset/request", handler.RequestPasswordReset)
+		auth.POST("/password-reset", handler.ResetPassword)
+	}
+
+	// enrollment stays reachable while a role's 2FA requirement is pending
+	mfa := router.Group("/auth/2fa", authenticate, session)
+	{
+		mfa.POST("/enroll", handler.EnrollMFA)
+		mfa.POST("/activate", handler.ActivateMFA)
+		mfa.POST("/disable", handler.DisableMFA)
+		mfa.POST("/recovery-codes", handler.RegenerateRecoveryCodes)
+	}
+
+	sessions := router.Group("/auth/sessions", authenticate, session)
+	{
+		sessions.GET("", handler.ListSessions)
+		sessions.DELETE("", handler.RevokeAllSessions)
+		sessions.DELETE("/:id", handler.RevokeSession)
+	}
+
+	admin := router.Group("/admin", authenticate, session, RequireRole(domain.RoleAdmin))
+	{
+		admin.GET("/2fa-policies", handler.ListMFAPolicies)
+		admin.PUT("/2fa-policies/:role", handler.SetMFAPolicy)
+		admin.GET("/lockouts", handler.ListLockouts)
+		admin.POST("/users/:id/unlock", handler.UnlockUser)
+		admin.DELETE("/users/:id/sessions", handler.TerminateUserSessions)
+		admin.PUT("/users/:id/role", handler.SetUserRole)
+		admin.GET("/auth-events", handler.ListAuthEvents)
+		admin.GET("/auth-events/export", handler.ExportAuthEvents)
+	}
+
+	apiKeys := router.Group("/auth/api-keys", authenticate, session)
+	{
+		apiKeys.POST("", RequireRole(domain.RoleSeller), handler.CreateAPIKey)
+		apiKeys.GET("", handler.ListAPIKeys)
+		apiKeys.DELETE("/:id", handler.RevokeAPIKey)
+	}
+}
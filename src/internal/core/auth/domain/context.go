@@ -0,0 +1,59 @@
+package domain
+
+import "context"
+
+type claimsKey struct{}
+
+type clientKey struct{}
+
+// ClientInfo describes where a request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// ContextWithClaims returns a copy of ctx carrying the authenticated caller.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the caller stored by the auth middleware.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// ContextWithClient returns a copy of ctx carrying the caller's address and
+// user agent.
+func ContextWithClient(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client stored by the auth routes, or an
+// empty ClientInfo.
+func ClientFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientKey{}).(ClientInfo)
+	return client
+}
+
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if c.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the caller may use a route requiring scope.
+func (c *Claims) HasScope(scope string) bool {
+	if c.APIKeyID == nil {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
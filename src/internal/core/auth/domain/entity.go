@@ -0,0 +1,444 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleSeller   = "seller"
+	RoleAdmin    = "admin"
+)
+
+var (
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrTokenReused        = errors.New("refresh token was already used; session revoked")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+	ErrAlreadyVerified    = errors.New("email address is already verified")
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFARequired        = errors.New("two-factor authentication is required for this role")
+	ErrMFANotAllowed      = errors.New("two-factor authentication is only available to sellers and admins")
+	ErrUnknownRole        = errors.New("unknown role")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts; try again later")
+	ErrTooManyRequests    = errors.New("too many requests; try again later")
+	ErrSessionNotFound    = errors.New("session not found")
+)
+
+// Purposes of the single-use tokens sent by email.
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+// APIKeyPrefix starts every API key so that the auth middleware can tell
+// keys from JWTs and secret scanners can recognise leaked keys.
+const APIKeyPrefix = "gmk_"
+
+// API key scopes. A key can only reach routes that require one of its
+// scopes; interactive logins are not limited by scopes.
+const (
+	ScopeProductsWrite = "products:write"
+	ScopeVariantsWrite = "variants:write"
+	ScopeStockWrite    = "stock:write"
+	ScopeOrdersRead    = "orders:read"
+)
+
+const (
+	RevokeReasonLogout         = "logout"
+	RevokeReasonReuse          = "reuse_detected"
+	RevokeReasonPasswordChange = "password_change"
+	RevokeReasonPasswordReset  = "password_reset"
+	RevokeReasonSignedOut      = "signed_out"
+	RevokeReasonAdmin          = "admin_terminated"
+	RevokeReasonAccountDeleted = "account_deleted"
+)
+
+type User struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash    string     `json:"-" gorm:"not null"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Role            string     `json:"role" gorm:"default:'customer'"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// UserToken is a single-use, time-limited token mailed to a user. Only its
+// hash is stored.
+type UserToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TokenFamily groups every refresh token rotated from a single login, and
+// is what users see as a session. When an already-rotated token is presented
+// again the whole family is revoked, cutting off both the legitimate client
+// and whoever replayed the token.
+type TokenFamily struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        uint       `json:"user_id" gorm:"not null"`
+	UserAgent     string     `json:"user_agent"`
+	IP            string     `json:"ip"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+	MFAVerified   bool       `json:"mfa_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Session is a signed-in device as shown to its user.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Current marks the session the request was made from.
+	Current bool `json:"current"`
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FamilyID  uuid.UUID  `json:"family_id" gorm:"type:uuid;not null"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// APIKey lets a seller's own systems call the API without an interactive
+// login. Only a hash of the key is stored; the key itself is shown once.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uint       `json:"user_id" gorm:"not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     Scopes     `json:"scopes" gorm:"type:text;not null"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Scopes is stored as a space separated list, the same way OAuth encodes
+// scopes.
+type Scopes []string
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	case nil:
+		*s = nil
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", src)
+	}
+	return nil
+}
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=products:write variants:write stock:write orders:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey is returned once, when the key is created.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// MFAEnrollment holds a user's TOTP secret. It is pending until the first
+// code is confirmed and EnabledAt is set.
+type MFAEnrollment struct {
+	UserID uint   `json:"user_id" gorm:"primaryKey"`
+	Secret string `json:"-" gorm:"not null"`
+	// LastUsedStep is the last accepted time step; a code is never accepted
+	// twice.
+	LastUsedStep int64      `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	CodeHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Types of AuthEvent.
+const (
+	EventRegistered               = "registered"
+	EventLoginSucceeded           = "login_succeeded"
+	EventLoginFailed              = "login_failed"
+	EventLoginLocked              = "login_locked"
+	EventLoginUnlocked            = "login_unlocked"
+	EventTokenRefreshed           = "token_refreshed"
+	EventTokenReused              = "token_reuse_detected"
+	EventLoggedOut                = "logged_out"
+	EventSessionRevoked           = "session_revoked"
+	EventSessionsRevoked          = "sessions_revoked"
+	EventPasswordChanged          = "password_changed"
+	EventPasswordReset            = "password_reset"
+	EventEmailVerified            = "email_verified"
+	EventMFAEnabled               = "mfa_enabled"
+	EventMFADisabled              = "mfa_disabled"
+	EventRecoveryCodesRegenerated = "mfa_recovery_codes_regenerated"
+	EventMFAPolicyChanged         = "mfa_policy_changed"
+	EventRoleGranted              = "role_granted"
+	EventAPIKeyCreated            = "api_key_created"
+	EventAPIKeyRevoked            = "api_key_revoked"
+)
+
+// AuthEvent is an entry in the append-only audit log of authentication.
+// ActorID is set when someone other than the user, such as an admin, caused
+// the event. Email is only kept for attempts on accounts that do not exist.
+type AuthEvent struct {
+	ID        uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Type      string       `json:"type" gorm:"not null"`
+	UserID    *uint        `json:"user_id,omitempty"`
+	ActorID   *uint        `json:"actor_id,omitempty"`
+	Email     string       `json:"email,omitempty"`
+	IP        string       `json:"ip,omitempty"`
+	UserAgent string       `json:"user_agent,omitempty"`
+	SessionID *uuid.UUID   `json:"session_id,omitempty" gorm:"type:uuid"`
+	Details   EventDetails `json:"details,omitempty" gorm:"type:jsonb"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// EventDetails holds what else is known about an event, such as why a
+// login failed. It is stored as a JSON object.
+type EventDetails map[string]string
+
+func (d EventDetails) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (d *EventDetails) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	case nil:
+		*d = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into EventDetails", src)
+	}
+}
+
+type AuthEventFilter struct {
+	UserID *uint
+	Type   string
+	From   *time.Time
+	To     *time.Time
+	Page   int
+	Limit  int
+}
+
+// Lockout scopes: failed logins are counted per account and per client
+// address, and failed second factors per account on their own.
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+	LockoutScopeMFA     = "mfa"
+)
+
+// LockedError is returned while login is locked. It wraps
+// ErrTooManyAttempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// LockoutEvent records a lockout for security review. UserID is empty when
+// the account does not exist or the address, not an account, was locked.
+type LockoutEvent struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Scope       string     `json:"scope" gorm:"not null"`
+	UserID      *uint      `json:"user_id,omitempty"`
+	Email       string     `json:"email,omitempty"`
+	IP          string     `json:"ip,omitempty"`
+	Attempts    int        `json:"attempts"`
+	LockedUntil time.Time  `json:"locked_until"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+	UnlockedBy  *uint      `json:"unlocked_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type LockoutFilter struct {
+	UserID *uint
+	Email  string
+	IP     string
+	Page   int
+	Limit  int
+}
+
+type MFARolePolicy struct {
+	Role      string    `json:"role" gorm:"primaryKey"`
+	Required  bool      `json:"required"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SetRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=customer seller admin"`
+}
+
+type RegisterInput struct {
+	Email     string `json:"email" validate:"required,email,max=255"`
+	Password  string `json:"password" validate:"required,min=8,max=72"`
+	FirstName string `json:"first_name" validate:"max=100"`
+	LastName  string `json:"last_name" validate:"max=100"`
+}
+
+type LoginInput struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type PasswordResetRequestInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type MFACodeInput struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFALoginInput struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code is a code from the authenticator app or an unused recovery code.
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMFAInput struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type SetMFAPolicyInput struct {
+	Required *bool `json:"required" validate:"required"`
+}
+
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are shown once; only their hashes are stored.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type UserWithToken struct {
+	User   *User     `json:"user"`
+	Tokens TokenPair `json:"tokens"`
+}
+
+// LoginResult either carries tokens or, when the account uses two-factor
+// authentication, a challenge token to finish the login with.
+type LoginResult struct {
+	User        *User      `json:"user,omitempty"`
+	Tokens      *TokenPair `json:"tokens,omitempty"`
+	MFARequired bool       `json:"mfa_required"`
+	// ChallengeToken is exchanged together with a code at /auth/login/2fa.
+	ChallengeToken string `json:"challenge_token,omitempty"`
+	ExpiresIn      int64  `json:"expires_in,omitempty"`
+}
+
+// Claims describes an authenticated caller. Callers authenticated with an
+// API key have APIKeyID and Scopes set.
+type Claims struct {
+	TokenID       string `json:"token_id,omitempty"`
+	UserID        uint   `json:"user_id"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+	// MFAPending is set when the caller's role requires two-factor
+	// authentication and this session did not pass it.
+	MFAPending bool `json:"mfa_pending,omitempty"`
+	// SessionID is the token family an access token was issued for; it is
+	// empty for API keys.
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	APIKeyID  *uuid.UUID `json:"api_key_id,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id uint) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	Update(ctx context.Context, user *User) error
+}
+
+type RefreshTokenRepository interface {
+	CreateFamily(ctx context.Context, family *TokenFamily) error
+	GetFamily(ctx context.Context, id uuid.UUID) (*TokenFamily, error)
+	RevokeFamily(ctx context.Context, id uuid.UUID, reason string) error
+	// RevokeUserFamily revokes one of the user's families. It returns
+	// gorm.ErrRecordNotFound if the user has no such unrevoked family.
+	RevokeUserFamily(ctx context.Context, userID uint, id uuid.UUID, reason string) error
+	RevokeUserFamilies(ctx context.Context, userID uint, reason string) error
+	// ListActiveFamilies returns the user's unrevoked families used since
+	// the given time, most recently used first.
+	ListActiveFamilies(ctx context.Context, userID uint, usedSince time.Time) ([]*TokenFamily, error)
+	// TouchFamily records a use of the family from client.
+	TouchFamily(ctx context.Context, id uuid.UUID, client ClientInfo, at time.Time) error
+	Create(ctx context.Context, token *RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// Rotate marks the old token as used and stores next in one transaction.
+	// It reports false, storing nothing, if the old token was already used.
+	Rotate(ctx context.Context, oldID uuid.UUID, next *RefreshToken) (bool, error)
+	// AnonymizeUserFamilies clears where the user's sessions were used from.
+	AnonymizeUserFamilies(ctx context.Context, userID uint) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListByUserID(ctx context.Context, userID uint) ([]*APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID, userID uint) error
+	// RevokeAllForUser revokes every active key of the user and returns how
+	// many there were.
+	RevokeAllForUser(ctx context.Context, userID uint) (int64, error)
+	// TouchLastUsed records a use, skipping the write if the key was already
+	// marked used within the last minute.
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *UserToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*UserToken, error)
+	// Consume marks an unused, unexpired token as used. It reports false if
+	// the token was already used or has expired.
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
+	// InvalidateUserTokens marks every outstanding token of the user for
+	// purpose as used.
+	InvalidateUserTokens(ctx context.Context, userID uint, purpose string) error
+}
+
+type MFARepository interface {
+	GetEnrollment(ctx context.Context, userID uint) (*MFAEnrollment, error)
+	SaveEnrollment(ctx context.Context, enrollment *MFAEnrollment) error
+	DeleteEnrollment(ctx context.Context, userID uint) error
+	// UseStep records step as used. It reports false if the same or a later
+	// step was already used.
+	UseStep(ctx context.Context, userID uint, step int64) (bool, error)
+
+	// ReplaceRecoveryCodes drops the user's recovery codes and stores new ones.
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []*MFARecoveryCode) error
+	// UseRecoveryCode marks an unused code as used and reports whether it was.
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+
+	ListPolicies(ctx context.Context) ([]*MFARolePolicy, error)
+	GetPolicy(ctx context.Context, role string) (*MFARolePolicy, error)
+	SavePolicy(ctx context.Context, policy *MFARolePolicy) error
+}
+
+type LockoutRepository interface {
+	Create(ctx context.Context, event *LockoutEvent) error
+	List(ctx context.Context, filter LockoutFilter) ([]*LockoutEvent, int64, error)
+	// MarkUnlocked records that an admin lifted the account's lockouts that
+	// are still in force.
+	MarkUnlocked(ctx context.Context, email string, adminID uint, at time.Time) error
+	// Anonymize clears the email and address of the lockouts of the user or
+	// of their email.
+	Anonymize(ctx context.Context, userID uint, email string) error
+}
+
+// AuthEventRepository only appends; events are never removed, and only
+// their personal fields are ever cleared.
+type AuthEventRepository interface {
+	Create(ctx context.Context, event *AuthEvent) error
+	List(ctx context.Context, filter AuthEventFilter) ([]*AuthEvent, int64, error)
+	// Stream calls fn for every event matching filter, oldest first,
+	// ignoring the filter's paging.
+	Stream(ctx context.Context, filter AuthEventFilter, fn func(*AuthEvent) error) error
+	// Anonymize clears the email, address and user agent of the events of
+	// the user or of their email.
+	Anonymize(ctx context.Context, userID uint, email string) error
+}
@@ -1,10 +1,50 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"github.com/google/uuid"
+)
 
 type AuthService interface {
 	Register(ctx context.Context, input RegisterInput) (*UserWithToken, error)
-	Login(ctx context.Context, email, password string) (*UserWithToken, error)
+	Login(ctx context.Context, email, password string) (*LoginResult, error)
+	CompleteMFALogin(ctx context.Context, input MFALoginInput) (*UserWithToken, error)
 	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 	ValidateToken(ctx context.Context, token string) (*Claims, error)
-}
\ No newline at end of file
+	Logout(ctx context.Context, claims *Claims, refreshToken string) error
+	ChangePassword(ctx context.Context, userID uint, input ChangePasswordInput) error
+
+	ListSessions(ctx context.Context, claims *Claims) ([]*Session, error)
+	RevokeSession(ctx context.Context, userID uint, id uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uint) error
+	TerminateUserSessions(ctx context.Context, adminID, userID uint) error
+	// RevokeUserAccess ends every session and revokes every API key of the
+	// user, for example when their account is being deleted.
+	RevokeUserAccess(ctx context.Context, userID uint, reason string) error
+
+	RequestEmailVerification(ctx context.Context, userID uint) error
+	VerifyEmail(ctx context.Context, input VerifyEmailInput) error
+	RequestPasswordReset(ctx context.Context, input PasswordResetRequestInput) error
+	ResetPassword(ctx context.Context, input ResetPasswordInput) error
+
+	EnrollMFA(ctx context.Context, userID uint) (*MFASetup, error)
+	ActivateMFA(ctx context.Context, userID uint, input MFACodeInput) (*RecoveryCodes, error)
+	DisableMFA(ctx context.Context, userID uint, input DisableMFAInput) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, input MFACodeInput) (*RecoveryCodes, error)
+	ListMFAPolicies(ctx context.Context) ([]*MFARolePolicy, error)
+	SetMFAPolicy(ctx context.Context, role string, input SetMFAPolicyInput) (*MFARolePolicy, error)
+
+	SetUserRole(ctx context.Context, userID uint, input SetRoleInput) (*User, error)
+	UnlockUser(ctx context.Context, adminID, userID uint) error
+	ListLockouts(ctx context.Context, filter LockoutFilter) ([]*LockoutEvent, int64, error)
+	ListAuthEvents(ctx context.Context, filter AuthEventFilter) ([]*AuthEvent, int64, error)
+	ExportAuthEvents(ctx context.Context, filter AuthEventFilter, fn func(*AuthEvent) error) error
+	// AnonymizeUserData clears the personal data that the user's sessions,
+	// lockouts and auth events keep. The records themselves stay for review.
+	AnonymizeUserData(ctx context.Context, userID uint) error
+
+	CreateAPIKey(ctx context.Context, userID uint, input CreateAPIKeyInput) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, userID uint) ([]*APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID uint, id uuid.UUID) error
+	ValidateAPIKey(ctx context.Context, key string) (*Claims, error)
+}
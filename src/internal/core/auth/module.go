@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/auth/delivery/http"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/auth/repository"
+	"golang_marketplace/src/internal/core/auth/service"
+	"golang_marketplace/src/internal/platform/cache"
+	"golang_marketplace/src/internal/platform/mailer"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+// CacheKeyPrefix starts every key the auth module keeps in the cache.
+const CacheKeyPrefix = "auth:"
+
+type Module struct {
+	Service domain.AuthService
+	logger  *slog.Logger
+}
+
+func NewModule(
+	db *gorm.DB,
+	cache cache.Cache,
+	mailer mailer.Mailer,
+	tokens service.TokenOptions,
+	account service.AccountOptions,
+	lockout service.LockoutOptions,
+	logger *slog.Logger,
+) *Module {
+	logger = logger.With("module", "auth")
+
+	userRepo := repository.NewUserRepository(db)
+	refreshRepo := repository.NewRefreshTokenRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	lockoutRepo := repository.NewLockoutRepository(db)
+	eventRepo := repository.NewAuthEventRepository(db)
+
+	authService := service.NewAuthService(
+		userRepo, refreshRepo, apiKeyRepo, userTokenRepo, mfaRepo, lockoutRepo, eventRepo,
+		cache, mailer, tokens, account, lockout, logger,
+	)
+
+	return &Module{
+		Service: authService,
+		logger:  logger,
+	}
+}
+
+func (m *Module) RegisterRoutes(router *gin.RouterGroup) {
+	http.RegisterRoutes(router, m.Service, m.logger)
+}
+
+// ClientInfo returns middleware recording the caller's address and user
+// agent. It is installed on the whole router so that every module's
+// requests carry them.
+func (m *Module) ClientInfo() gin.HandlerFunc {
+	return http.ClientInfo()
+}
+
+// Authenticate returns middleware that other modules put in front of routes
+// requiring a logged-in caller.
+func (m *Module) Authenticate() gin.HandlerFunc {
+	return http.Authenticate(m.Service, m.logger)
+}
+
+// OptionalAuthenticate returns middleware that identifies callers on public
+// routes without requiring them to log in.
+func (m *Module) OptionalAuthenticate() gin.HandlerFunc {
+	return http.OptionalAuthenticate(m.Service, m.logger)
+}
+
+// ExportUserEvents collects a user's auth events for their personal data
+// export.
+func (m *Module) ExportUserEvents(ctx context.Context, userID uint) (interface{}, error) {
+	events := []*domain.AuthEvent{}
+	err := m.Service.ExportAuthEvents(ctx, domain.AuthEventFilter{UserID: &userID}, func(event *domain.AuthEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+// RevokeDeletedUserAccess signs a user whose account is being deleted out
+// everywhere and revokes their API keys.
+func (m *Module) RevokeDeletedUserAccess(ctx context.Context, userID uint) error {
+	return m.Service.RevokeUserAccess(ctx, userID, domain.RevokeReasonAccountDeleted)
+}
+
+// AnonymizeDeletedUser revokes a deleted user's access and then clears the
+// personal data of their sessions, lockouts and auth events, including the
+// event recording the revocation.
+func (m *Module) AnonymizeDeletedUser(ctx context.Context, userID uint) error {
+	if err := m.RevokeDeletedUserAccess(ctx, userID); err != nil {
+		return err
+	}
+	return m.Service.AnonymizeUserData(ctx, userID)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+// lastUsedResolution limits how often a busy key writes its last use.
+const lastUsedResolution = time.Minute
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) domain.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	// a revoked key must stop working at once, not once the replicas catch up
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&key, "key_hash = ?", keyHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID uint) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID, userID uint) error {
+	result := r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeAllForUser(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-lastUsedResolution)).
+		Update("last_used_at", at).Error
+}
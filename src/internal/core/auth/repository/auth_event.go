@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many events Stream reads per query.
+const exportBatchSize = 500
+
+type authEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthEventRepository(db *gorm.DB) domain.AuthEventRepository {
+	return &authEventRepository{db: db}
+}
+
+func (r *authEventRepository) Create(ctx context.Context, event *domain.AuthEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// Anonymize is the only update the table's trigger lets through.
+func (r *authEventRepository) Anonymize(ctx context.Context, userID uint, email string) error {
+	return r.db.WithContext(ctx).Model(&domain.AuthEvent{}).
+		Where("user_id = ? OR email = ?", userID, email).
+		Updates(map[string]interface{}{"email": nil, "ip": nil, "user_agent": nil}).Error
+}
+
+func (r *authEventRepository) List(ctx context.Context, filter domain.AuthEventFilter) ([]*domain.AuthEvent, int64, error) {
+	var events []*domain.AuthEvent
+	var total int64
+
+	query := r.applyFilters(r.db.WithContext(ctx).Model(&domain.AuthEvent{}), filter)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page > 0 && filter.Limit > 0 {
+		query = query.Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *authEventRepository) Stream(ctx context.Context, filter domain.AuthEventFilter, fn func(*domain.AuthEvent) error) error {
+	var batch []*domain.AuthEvent
+	query := r.applyFilters(r.db.WithContext(ctx).Model(&domain.AuthEvent{}), filter).
+		Order("created_at, id").
+		Session(&gorm.Session{})
+
+	// keyset paging keeps each query cheap however far the export has got
+	for {
+		page := query
+		if n := len(batch); n > 0 {
+			last := batch[n-1]
+			page = page.Where("(created_at, id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		batch = batch[:0]
+		if err := page.Limit(exportBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, event := range batch {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *authEventRepository) applyFilters(query *gorm.DB, filter domain.AuthEventFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
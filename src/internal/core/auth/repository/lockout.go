@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+type lockoutRepository struct {
+	db *gorm.DB
+}
+
+func NewLockoutRepository(db *gorm.DB) domain.LockoutRepository {
+	return &lockoutRepository{db: db}
+}
+
+func (r *lockoutRepository) Create(ctx context.Context, event *domain.LockoutEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *lockoutRepository) List(ctx context.Context, filter domain.LockoutFilter) ([]*domain.LockoutEvent, int64, error) {
+	var events []*domain.LockoutEvent
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.LockoutEvent{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page > 0 && filter.Limit > 0 {
+		query = query.Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit)
+	}
+
+	if err := query.Order("created_at DESC").Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+func (r *lockoutRepository) MarkUnlocked(ctx context.Context, email string, adminID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.LockoutEvent{}).
+		Where("scope IN ? AND email = ? AND unlocked_at IS NULL AND locked_until > ?",
+			[]string{domain.LockoutScopeAccount, domain.LockoutScopeMFA}, email, at).
+		Updates(map[string]interface{}{"unlocked_at": at, "unlocked_by": adminID}).Error
+}
+
+func (r *lockoutRepository) Anonymize(ctx context.Context, userID uint, email string) error {
+	return r.db.WithContext(ctx).Model(&domain.LockoutEvent{}).
+		Where("user_id = ? OR email = ?", userID, email).
+		Updates(map[string]interface{}{"email": nil, "ip": nil}).Error
+}
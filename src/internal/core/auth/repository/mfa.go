@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) domain.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetEnrollment(ctx context.Context, userID uint) (*domain.MFAEnrollment, error) {
+	var enrollment domain.MFAEnrollment
+	// read right after enrolling and on every login, so skip replicas
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&enrollment, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *mfaRepository) SaveEnrollment(ctx context.Context, enrollment *domain.MFAEnrollment) error {
+	return r.db.WithContext(ctx).Save(enrollment).Error
+}
+
+func (r *mfaRepository) DeleteEnrollment(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MFARecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.MFAEnrollment{}, "user_id = ?", userID).Error
+	})
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.MFAEnrollment{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []*domain.MFARecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MFARecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Create(codes).Error
+	})
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *mfaRepository) ListPolicies(ctx context.Context) ([]*domain.MFARolePolicy, error) {
+	var policies []*domain.MFARolePolicy
+	err := r.db.WithContext(ctx).Order("role").Find(&policies).Error
+	return policies, err
+}
+
+func (r *mfaRepository) GetPolicy(ctx context.Context, role string) (*domain.MFARolePolicy, error) {
+	var policy domain.MFARolePolicy
+	err := r.db.WithContext(ctx).First(&policy, "role = ?", role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *mfaRepository) SavePolicy(ctx context.Context, policy *domain.MFARolePolicy) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role"}},
+			DoUpdates: clause.AssignmentColumns([]string{"required", "updated_at"}),
+		}).
+		Create(policy).Error
+}
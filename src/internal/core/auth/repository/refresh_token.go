@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) domain.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) CreateFamily(ctx context.Context, family *domain.TokenFamily) error {
+	return r.db.WithContext(ctx).Create(family).Error
+}
+
+func (r *refreshTokenRepository) GetFamily(ctx context.Context, id uuid.UUID) (*domain.TokenFamily, error) {
+	var family domain.TokenFamily
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&family, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &family, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.db.WithContext(ctx).Model(&domain.TokenFamily{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+func (r *refreshTokenRepository) RevokeUserFamily(ctx context.Context, userID uint, id uuid.UUID, reason string) error {
+	result := r.db.WithContext(ctx).Model(&domain.TokenFamily{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeUserFamilies(ctx context.Context, userID uint, reason string) error {
+	return r.db.WithContext(ctx).Model(&domain.TokenFamily{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+func (r *refreshTokenRepository) ListActiveFamilies(ctx context.Context, userID uint, usedSince time.Time) ([]*domain.TokenFamily, error) {
+	var families []*domain.TokenFamily
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND last_used_at > ?", userID, usedSince).
+		Order("last_used_at DESC").
+		Find(&families).Error
+	return families, err
+}
+
+func (r *refreshTokenRepository) TouchFamily(ctx context.Context, id uuid.UUID, client domain.ClientInfo, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.TokenFamily{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": at, "ip": client.IP, "user_agent": client.UserAgent}).Error
+}
+
+func (r *refreshTokenRepository) AnonymizeUserFamilies(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&domain.TokenFamily{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"ip": nil, "user_agent": nil}).Error
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	// a replica could miss a rotation that just happened and hide a reuse
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, oldID uuid.UUID, next *domain.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", oldID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+
+	return rotated, err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) domain.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.UserToken, error) {
+	var token domain.UserToken
+	// links are often opened seconds after the mail is sent
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *userTokenRepository) InvalidateUserTokens(ctx context.Context, userID uint, purpose string) error {
+	return r.db.WithContext(ctx).Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/mailer"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AccountOptions configures the emails sent to verify an address or reset a
+// password.
+type AccountOptions struct {
+	// AppURL is the base of the links put in emails.
+	AppURL           string
+	VerificationTTL  time.Duration
+	PasswordResetTTL time.Duration
+}
+
+const verificationEmail = `Hello %s,
+
+Please confirm your email address by opening the link below:
+
+%s
+
+The link expires in %s. If you did not create an account, you can ignore this email.
+`
+
+const passwordResetEmail = `Hello %s,
+
+Someone asked to reset the password of your account. To choose a new password, open the link below:
+
+%s
+
+The link expires in %s and can only be used once. If you did not ask for this, you can ignore this email; your password has not been changed.
+`
+
+func (s *authService) RequestEmailVerification(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerified() {
+		return domain.ErrAlreadyVerified
+	}
+	if err := s.limitMail(ctx, mailKindVerification, user.Email); err != nil {
+		return err
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *authService) VerifyEmail(ctx context.Context, input domain.VerifyEmailInput) error {
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+
+	user, err := s.consumeUserToken(ctx, input.Token, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventEmailVerified, UserID: &user.ID})
+	return nil
+}
+
+// RequestPasswordReset mails a reset link if the account exists. It succeeds
+// either way so that it cannot be used to find out which emails are
+// registered, unless the address or the client asked too often.
+func (s *authService) RequestPasswordReset(ctx context.Context, input domain.PasswordResetRequestInput) error {
+	input.Email = normalizeEmail(input.Email)
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+	if err := s.limitMail(ctx, mailKindPasswordReset, input.Email); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, input.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// only the latest link works
+	if err := s.userTokenRepo.InvalidateUserTokens(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	token, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposePasswordReset, s.account.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf(passwordResetEmail, displayName(user), s.link("/reset-password", token), s.account.PasswordResetTTL),
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to send password reset email", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a mailed token and ends every
+// session of the user. Following the link proves control of the mailbox,
+// so it also verifies the email address.
+func (s *authService) ResetPassword(ctx context.Context, input domain.ResetPasswordInput) error {
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+
+	user, err := s.consumeUserToken(ctx, input.Token, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hash)
+	if !user.EmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventPasswordReset, UserID: &user.ID})
+
+	return s.revokeUserSessions(ctx, user.ID, domain.RevokeReasonPasswordReset)
+}
+
+func (s *authService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	if err := s.userTokenRepo.InvalidateUserTokens(ctx, user.ID, domain.TokenPurposeEmailVerification); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	token, err := s.issueUserToken(ctx, user.ID, domain.TokenPurposeEmailVerification, s.account.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Confirm your email address",
+		Body:    fmt.Sprintf(verificationEmail, displayName(user), s.link("/verify-email", token), s.account.VerificationTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) issueUserToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := s.userTokenRepo.Create(ctx, &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	return token, nil
+}
+
+// consumeUserToken spends a mailed token and returns the user it was issued
+// to. Unknown, expired, spent and wrong-purpose tokens all fail the same way.
+func (s *authService) consumeUserToken(ctx context.Context, token, purpose string) (*domain.User, error) {
+	record, err := s.userTokenRepo.GetByHash(ctx, hashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	if record.Purpose != purpose {
+		return nil, domain.ErrInvalidToken
+	}
+
+	consumed, err := s.userTokenRepo.Consume(ctx, record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+	if !consumed {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, record.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *authService) link(path, token string) string {
+	return strings.TrimRight(s.account.AppURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func displayName(user *domain.User) string {
+	if user.FirstName != "" {
+		return user.FirstName
+	}
+	return user.Email
+}
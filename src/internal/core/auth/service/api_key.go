@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// apiKeyDisplayLength is how much of the key is kept in clear text so that
+// users can tell their keys apart.
+const apiKeyDisplayLength = len(domain.APIKeyPrefix) + 8
+
+func (s *authService) CreateAPIKey(ctx context.Context, userID uint, input domain.CreateAPIKeyInput) (*domain.CreatedAPIKey, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", validator.ErrValidation)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := domain.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &domain.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(input.Name),
+		Prefix:    secret[:apiKeyDisplayLength],
+		KeyHash:   hashToken(secret),
+		Scopes:    dedupe(input.Scopes),
+		ExpiresAt: input.ExpiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventAPIKeyCreated,
+		UserID:  &userID,
+		Details: domain.EventDetails{"api_key_id": key.ID.String(), "scopes": strings.Join(key.Scopes, " ")},
+	})
+
+	return &domain.CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+func (s *authService) ListAPIKeys(ctx context.Context, userID uint) ([]*domain.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (s *authService) RevokeAPIKey(ctx context.Context, userID uint, id uuid.UUID) error {
+	err := s.apiKeyRepo.Revoke(ctx, id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventAPIKeyRevoked,
+		UserID:  &userID,
+		Details: domain.EventDetails{"api_key_id": id.String()},
+	})
+	return nil
+}
+
+// ValidateAPIKey resolves a key to the claims of its owner, limited to the
+// key's scopes.
+func (s *authService) ValidateAPIKey(ctx context.Context, key string) (*domain.Claims, error) {
+	if !strings.HasPrefix(key, domain.APIKeyPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// the owner's current role applies, so demoting a seller disables their keys
+	user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to record api key use: %w", err)
+	}
+
+	claims := &domain.Claims{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		APIKeyID:      &apiKey.ID,
+		Scopes:        apiKey.Scopes,
+		IssuedAt:      apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt != nil {
+		claims.ExpiresAt = *apiKey.ExpiresAt
+	}
+	return claims, nil
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			result = append(result, v)
+		}
+	}
+	return result
+}
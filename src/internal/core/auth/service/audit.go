@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"time"
+)
+
+// recordEvent appends an event to the audit log, filling in the client and,
+// when a signed-in caller acts on another user's account, the actor. The
+// log must not get in the way of signing in, so a failed write is logged
+// rather than returned.
+func (s *authService) recordEvent(ctx context.Context, event *domain.AuthEvent) {
+	client := clientInfo(ctx)
+	event.IP = client.IP
+	event.UserAgent = client.UserAgent
+
+	if claims, ok := domain.ClaimsFromContext(ctx); ok {
+		if event.UserID == nil || *event.UserID != claims.UserID {
+			actorID := claims.UserID
+			event.ActorID = &actorID
+		}
+		if event.SessionID == nil {
+			event.SessionID = claims.SessionID
+		}
+	}
+
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record auth event", "type", event.Type, "error", err)
+	}
+}
+
+func (s *authService) ListAuthEvents(ctx context.Context, filter domain.AuthEventFilter) ([]*domain.AuthEvent, int64, error) {
+	events, total, err := s.eventRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list auth events: %w", err)
+	}
+	return events, total, nil
+}
+
+// ExportAuthEvents calls fn for every matching event, oldest first.
+func (s *authService) ExportAuthEvents(ctx context.Context, filter domain.AuthEventFilter, fn func(*domain.AuthEvent) error) error {
+	if err := s.eventRepo.Stream(ctx, filter, fn); err != nil {
+		return fmt.Errorf("failed to export auth events: %w", err)
+	}
+	return nil
+}
+
+// AnonymizeUserData clears the user's email, addresses and user agents
+// wherever the auth module keeps them for review. Events and lockouts
+// recorded against the email alone, such as failed logins before the
+// account existed, are cleared as well.
+func (s *authService) AnonymizeUserData(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.refreshRepo.AnonymizeUserFamilies(ctx, userID); err != nil {
+		return fmt.Errorf("failed to anonymize sessions: %w", err)
+	}
+	if err := s.lockoutRepo.Anonymize(ctx, userID, user.Email); err != nil {
+		return fmt.Errorf("failed to anonymize lockouts: %w", err)
+	}
+	if err := s.eventRepo.Anonymize(ctx, userID, user.Email); err != nil {
+		return fmt.Errorf("failed to anonymize auth events: %w", err)
+	}
+	return nil
+}
+
+// SetUserRole changes a user's role. Access tokens carrying the old role are
+// denied so that the change takes effect on the next refresh rather than
+// when they expire.
+func (s *authService) SetUserRole(ctx context.Context, userID uint, input domain.SetRoleInput) (*domain.User, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Role == input.Role {
+		return user, nil
+	}
+
+	previous := user.Role
+	user.Role = input.Role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if err := s.denylist.denyUserTokens(ctx, user.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventRoleGranted,
+		UserID:  &user.ID,
+		Details: domain.EventDetails{"role": user.Role, "previous_role": previous},
+	})
+	return user, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"testing"
+)
+
+type anonymizedFamilies struct {
+	domain.RefreshTokenRepository
+	userIDs []uint
+}
+
+func (r *anonymizedFamilies) AnonymizeUserFamilies(_ context.Context, userID uint) error {
+	r.userIDs = append(r.userIDs, userID)
+	return nil
+}
+
+type anonymizedLockouts struct {
+	stubLockouts
+	emails []string
+}
+
+func (r *anonymizedLockouts) Anonymize(_ context.Context, _ uint, email string) error {
+	r.emails = append(r.emails, email)
+	return nil
+}
+
+type anonymizedEvents struct {
+	stubEvents
+	emails []string
+}
+
+func (r *anonymizedEvents) Anonymize(_ context.Context, _ uint, email string) error {
+	r.emails = append(r.emails, email)
+	return nil
+}
+
+func TestAnonymizeUserData(t *testing.T) {
+	ctx := context.Background()
+	s := newMFAService(t, 5)
+	families := &anonymizedFamilies{}
+	lockouts := &anonymizedLockouts{}
+	events := &anonymizedEvents{}
+	s.refreshRepo, s.lockoutRepo, s.eventRepo = families, lockouts, events
+
+	if err := s.AnonymizeUserData(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(families.userIDs) != 1 || families.userIDs[0] != 1 {
+		t.Errorf("sessions anonymized for %v, want [1]", families.userIDs)
+	}
+	// events of the email alone, such as failures before signing up, go too
+	for name, emails := range map[string][]string{"lockouts": lockouts.emails, "events": events.emails} {
+		if len(emails) != 1 || emails[0] != "seller@example.com" {
+			t.Errorf("%s anonymized for %v, want the user's email", name, emails)
+		}
+	}
+
+	// an account that is already gone has nothing left to clear
+	if err := s.AnonymizeUserData(ctx, 2); err != nil {
+		t.Errorf("AnonymizeUserData of an unknown user = %v", err)
+	}
+}
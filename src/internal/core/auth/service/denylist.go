@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/platform/cache"
+	"strconv"
+	"time"
+)
+
+// denylist rejects access tokens before they expire. Single tokens are
+// denied by ID on logout; all tokens of a session are denied when it is
+// revoked; all tokens of a user issued before a point in time are denied
+// after a password change. Entries only need to outlive the longest-lived
+// access token.
+//
+// With the memory cache driver the denylist is per process, so deployments
+// running more than one instance should use redis.
+type denylist struct {
+	cache     cache.Cache
+	accessTTL time.Duration
+}
+
+func deniedTokenKey(tokenID string) string {
+	return "auth:denied:" + tokenID
+}
+
+func revokedSessionKey(sessionID uuid.UUID) string {
+	return "auth:revoked_session:" + sessionID.String()
+}
+
+func revokedBeforeKey(userID uint) string {
+	return "auth:revoked_before_ms:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func (d *denylist) denyToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := d.cache.Set(ctx, deniedTokenKey(tokenID), true, ttl); err != nil {
+		return fmt.Errorf("failed to deny token: %w", err)
+	}
+	return nil
+}
+
+func (d *denylist) denySession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := d.cache.Set(ctx, revokedSessionKey(sessionID), true, d.accessTTL); err != nil {
+		return fmt.Errorf("failed to deny session tokens: %w", err)
+	}
+	return nil
+}
+
+func (d *denylist) denyUserTokens(ctx context.Context, userID uint, before time.Time) error {
+	if err := d.cache.Set(ctx, revokedBeforeKey(userID), before.UnixMilli(), d.accessTTL); err != nil {
+		return fmt.Errorf("failed to deny user tokens: %w", err)
+	}
+	return nil
+}
+
+// isDenied fails closed: if the cache cannot be read the token is treated as
+// unusable rather than silently accepted.
+func (d *denylist) isDenied(ctx context.Context, tokenID string, sessionID *uuid.UUID, userID uint, issuedAt time.Time) (bool, error) {
+	denied, err := d.cache.Exists(ctx, deniedTokenKey(tokenID))
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+	if denied {
+		return true, nil
+	}
+
+	if sessionID != nil {
+		denied, err = d.cache.Exists(ctx, revokedSessionKey(*sessionID))
+		if err != nil {
+			return false, fmt.Errorf("failed to check token denylist: %w", err)
+		}
+		if denied {
+			return true, nil
+		}
+	}
+
+	var revokedBefore int64
+	err = d.cache.Get(ctx, revokedBeforeKey(userID), &revokedBefore)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %w", err)
+	}
+
+	// a token issued in the same millisecond may predate the revocation
+	return issuedAt.UnixMilli() <= revokedBefore, nil
+}
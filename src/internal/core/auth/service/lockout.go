@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// lockoutHistory is how long a lockout counts towards the length of the
+// next one.
+const lockoutHistory = 24 * time.Hour
+
+type LockoutOptions struct {
+	// MaxAttempts failed logins for one account within Window lock it.
+	MaxAttempts int
+	// IPMaxAttempts failed logins from one address within Window lock the
+	// address, whichever accounts they were for.
+	IPMaxAttempts int
+	Window        time.Duration
+	// Duration is the length of the first lockout. Every further lockout
+	// within a day doubles it, up to MaxDuration.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// loginThrottle counts failed logins per account and per client address in
+// the cache and locks either one out once it reaches its limit. Accounts
+// are keyed by email, whether or not they exist, so that a lockout does not
+// reveal which emails are registered.
+type loginThrottle struct {
+	cache  cache.Cache
+	repo   domain.LockoutRepository
+	opts   LockoutOptions
+	logger *slog.Logger
+}
+
+type lockTarget struct {
+	scope string
+	id    string
+	limit int
+}
+
+func loginFailuresKey(scope, id string) string {
+	return "auth:login_failures:" + scope + ":" + id
+}
+
+func loginLockKey(scope, id string) string {
+	return "auth:login_locked:" + scope + ":" + id
+}
+
+func lockoutsKey(scope, id string) string {
+	return "auth:lockouts:" + scope + ":" + id
+}
+
+func (t *loginThrottle) targets(email, ip string) []lockTarget {
+	targets := []lockTarget{{scope: domain.LockoutScopeAccount, id: email, limit: t.opts.MaxAttempts}}
+	if ip != "" {
+		targets = append(targets, lockTarget{scope: domain.LockoutScopeIP, id: ip, limit: t.opts.IPMaxAttempts})
+	}
+	return targets
+}
+
+// mfaTarget counts failed second factors for an account. It is kept apart
+// from the password count, which a correct password would not reach, so
+// that new challenges do not bring a fresh budget of codes.
+func (t *loginThrottle) mfaTarget(email string) lockTarget {
+	return lockTarget{scope: domain.LockoutScopeMFA, id: email, limit: t.opts.MaxAttempts}
+}
+
+// check returns a LockedError while the account or the address is locked.
+func (t *loginThrottle) check(ctx context.Context, email, ip string) error {
+	return t.checkTargets(ctx, t.targets(email, ip))
+}
+
+// checkMFA returns a LockedError while the account's second factor is
+// locked.
+func (t *loginThrottle) checkMFA(ctx context.Context, email string) error {
+	return t.checkTargets(ctx, []lockTarget{t.mfaTarget(email)})
+}
+
+func (t *loginThrottle) checkTargets(ctx context.Context, targets []lockTarget) error {
+	var retryAfter time.Duration
+	for _, target := range targets {
+		remaining, err := t.lockRemaining(ctx, target)
+		if err != nil {
+			return err
+		}
+		if remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	if retryAfter > 0 {
+		return &domain.LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// lockRemaining returns how long the target stays locked, or zero if it is
+// not locked.
+func (t *loginThrottle) lockRemaining(ctx context.Context, target lockTarget) (time.Duration, error) {
+	var until int64
+	err := t.cache.Get(ctx, loginLockKey(target.scope, target.id), &until)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check login lockout: %w", err)
+	}
+	return max(time.Until(time.UnixMilli(until)), 0), nil
+}
+
+// fail counts a failed login. It returns a LockedError if the attempt
+// locked the account or the address.
+func (t *loginThrottle) fail(ctx context.Context, email, ip string, userID *uint) error {
+	return t.failTargets(ctx, t.targets(email, ip), email, ip, userID)
+}
+
+// failMFA counts a failed second factor. It returns a LockedError if the
+// attempt locked the account's second factor.
+func (t *loginThrottle) failMFA(ctx context.Context, email, ip string, userID *uint) error {
+	return t.failTargets(ctx, []lockTarget{t.mfaTarget(email)}, email, ip, userID)
+}
+
+func (t *loginThrottle) failTargets(ctx context.Context, targets []lockTarget, email, ip string, userID *uint) error {
+	var locked *domain.LockedError
+	for _, target := range targets {
+		attempts, err := t.cache.Incr(ctx, loginFailuresKey(target.scope, target.id), t.opts.Window)
+		if err != nil {
+			return fmt.Errorf("failed to count failed login: %w", err)
+		}
+		if attempts < int64(target.limit) {
+			continue
+		}
+		// the failure that reaches the limit locks, and lock resets the
+		// count. A higher count means failures raced that one, or its lock
+		// did not take; only the latter locks again, so that concurrent
+		// failures do not stack lockouts.
+		if attempts > int64(target.limit) {
+			remaining, err := t.lockRemaining(ctx, target)
+			if err != nil {
+				return err
+			}
+			if remaining > 0 {
+				if locked == nil || remaining > locked.RetryAfter {
+					locked = &domain.LockedError{RetryAfter: remaining}
+				}
+				continue
+			}
+		}
+
+		event := &domain.LockoutEvent{
+			Scope:    target.scope,
+			Email:    email,
+			IP:       ip,
+			Attempts: int(attempts),
+		}
+		if target.scope != domain.LockoutScopeIP {
+			event.UserID = userID
+		}
+		lockErr, err := t.lock(ctx, target, event)
+		if err != nil {
+			return err
+		}
+		if locked == nil || lockErr.RetryAfter > locked.RetryAfter {
+			locked = lockErr
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+func (t *loginThrottle) lock(ctx context.Context, target lockTarget, event *domain.LockoutEvent) (*domain.LockedError, error) {
+	lockouts, err := t.cache.Incr(ctx, lockoutsKey(target.scope, target.id), lockoutHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lockouts: %w", err)
+	}
+
+	duration := t.backoff(lockouts)
+	event.LockedUntil = time.Now().Add(duration)
+
+	if err := t.cache.Set(ctx, loginLockKey(target.scope, target.id), event.LockedUntil.UnixMilli(), duration); err != nil {
+		return nil, fmt.Errorf("failed to lock login: %w", err)
+	}
+	if err := t.cache.Delete(ctx, loginFailuresKey(target.scope, target.id)); err != nil {
+		return nil, fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+
+	t.logger.WarnContext(ctx, "login locked",
+		"scope", event.Scope, "email", event.Email, "ip", event.IP,
+		"attempts", event.Attempts, "locked_until", event.LockedUntil)
+
+	// the lock is in place either way; losing the record only costs the review
+	if err := t.repo.Create(ctx, event); err != nil {
+		t.logger.ErrorContext(ctx, "failed to record lockout", "scope", event.Scope, "error", err)
+	}
+
+	return &domain.LockedError{RetryAfter: duration}, nil
+}
+
+// backoff returns the length of the nth lockout within lockoutHistory.
+func (t *loginThrottle) backoff(n int64) time.Duration {
+	duration := t.opts.Duration
+	for i := int64(1); i < n && duration < t.opts.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > t.opts.MaxDuration {
+		duration = t.opts.MaxDuration
+	}
+	return duration
+}
+
+// succeed clears the account's failed logins and second factors once the
+// login is complete. The address keeps its count so that logging into one
+// account does not reset a spray across many.
+func (t *loginThrottle) succeed(ctx context.Context, email string) error {
+	err := t.cache.Delete(ctx,
+		loginFailuresKey(domain.LockoutScopeAccount, email),
+		loginFailuresKey(domain.LockoutScopeMFA, email))
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// unlock lifts the account's lockouts, of the password and of the second
+// factor, and forgets their earlier ones.
+func (t *loginThrottle) unlock(ctx context.Context, email string) error {
+	var keys []string
+	for _, scope := range []string{domain.LockoutScopeAccount, domain.LockoutScopeMFA} {
+		keys = append(keys, loginLockKey(scope, email), loginFailuresKey(scope, email), lockoutsKey(scope, email))
+	}
+	if err := t.cache.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to unlock login: %w", err)
+	}
+	return nil
+}
+
+// loginFailed records a failed login and returns the error to report for it.
+func (s *authService) loginFailed(ctx context.Context, email, ip string, userID *uint) error {
+	event := &domain.AuthEvent{Type: domain.EventLoginFailed, UserID: userID}
+	if userID == nil {
+		event.Email = email
+		event.Details = domain.EventDetails{"reason": "unknown_account"}
+	} else {
+		event.Details = domain.EventDetails{"reason": "invalid_password"}
+	}
+	s.recordEvent(ctx, event)
+
+	err := s.throttle.fail(ctx, email, ip, userID)
+	var locked *domain.LockedError
+	if errors.As(err, &locked) {
+		s.recordEvent(ctx, &domain.AuthEvent{
+			Type:    domain.EventLoginLocked,
+			UserID:  userID,
+			Email:   event.Email,
+			Details: domain.EventDetails{"retry_after": locked.RetryAfter.String()},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// UnlockUser lifts a lockout on the user's account before it runs out.
+// Lockouts of client addresses expire on their own.
+func (s *authService) UnlockUser(ctx context.Context, adminID, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.throttle.unlock(ctx, user.Email); err != nil {
+		return err
+	}
+	if err := s.lockoutRepo.MarkUnlocked(ctx, user.Email, adminID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record unlock: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventLoginUnlocked, UserID: &user.ID})
+
+	s.logger.InfoContext(ctx, "login unlocked", "user_id", user.ID, "admin_id", adminID)
+	return nil
+}
+
+func (s *authService) ListLockouts(ctx context.Context, filter domain.LockoutFilter) ([]*domain.LockoutEvent, int64, error) {
+	filter.Email = normalizeEmail(filter.Email)
+
+	events, total, err := s.lockoutRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list lockouts: %w", err)
+	}
+	return events, total, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type countedLockouts struct {
+	stubLockouts
+	created int
+}
+
+func (r *countedLockouts) Create(context.Context, *domain.LockoutEvent) error {
+	r.created++
+	return nil
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	throttle := &loginThrottle{opts: LockoutOptions{Duration: time.Minute, MaxDuration: 10 * time.Minute}}
+
+	tests := []struct {
+		lockouts int64
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := throttle.backoff(tt.lockouts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.lockouts, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	newThrottle := func() *loginThrottle {
+		return &loginThrottle{
+			cache:  cache.NewMemoryCache(100),
+			repo:   stubLockouts{},
+			opts:   LockoutOptions{MaxAttempts: 3, IPMaxAttempts: 5, Window: time.Hour, Duration: time.Minute, MaxDuration: time.Hour},
+			logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+	}
+
+	t.Run("account locks at its limit", func(t *testing.T) {
+		throttle := newThrottle()
+		for i := 1; i <= 3; i++ {
+			err := throttle.fail(ctx, "a@example.com", "192.0.2.1", nil)
+			if locked := errors.Is(err, domain.ErrTooManyAttempts); locked != (i == 3) {
+				t.Fatalf("attempt %d: err = %v", i, err)
+			}
+		}
+		if err := throttle.check(ctx, "a@example.com", ""); !errors.Is(err, domain.ErrTooManyAttempts) {
+			t.Errorf("check after lockout: err = %v", err)
+		}
+		if err := throttle.check(ctx, "b@example.com", ""); err != nil {
+			t.Errorf("other account: err = %v", err)
+		}
+	})
+
+	t.Run("address locks across accounts", func(t *testing.T) {
+		throttle := newThrottle()
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+			_ = throttle.fail(ctx, email, "192.0.2.1", nil)
+		}
+		if err := throttle.check(ctx, "new@example.com", "192.0.2.1"); !errors.Is(err, domain.ErrTooManyAttempts) {
+			t.Errorf("check from the address: err = %v", err)
+		}
+		if err := throttle.check(ctx, "new@example.com", "192.0.2.2"); err != nil {
+			t.Errorf("check from another address: err = %v", err)
+		}
+	})
+
+	t.Run("success clears the account but not the address", func(t *testing.T) {
+		throttle := newThrottle()
+		for i := 0; i < 2; i++ {
+			_ = throttle.fail(ctx, "a@example.com", "192.0.2.1", nil)
+		}
+		if err := throttle.succeed(ctx, "a@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if err := throttle.fail(ctx, "a@example.com", "192.0.2.1", nil); err != nil {
+			t.Errorf("first failure after success: err = %v", err)
+		}
+		// two before, one after: the fourth and fifth lock the address
+		_ = throttle.fail(ctx, "b@example.com", "192.0.2.1", nil)
+		if err := throttle.fail(ctx, "c@example.com", "192.0.2.1", nil); !errors.Is(err, domain.ErrTooManyAttempts) {
+			t.Errorf("address count was reset: err = %v", err)
+		}
+	})
+
+	t.Run("unlock lifts password and second factor locks", func(t *testing.T) {
+		throttle := newThrottle()
+		for i := 0; i < 3; i++ {
+			_ = throttle.fail(ctx, "a@example.com", "", nil)
+			_ = throttle.failMFA(ctx, "a@example.com", "", nil)
+		}
+		if err := throttle.unlock(ctx, "a@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if err := throttle.check(ctx, "a@example.com", ""); err != nil {
+			t.Errorf("password still locked: %v", err)
+		}
+		if err := throttle.checkMFA(ctx, "a@example.com"); err != nil {
+			t.Errorf("second factor still locked: %v", err)
+		}
+	})
+	t.Run("failures past the limit lock only once", func(t *testing.T) {
+		throttle := newThrottle()
+		lockouts := &countedLockouts{}
+		throttle.repo = lockouts
+		for i := 0; i < 3; i++ {
+			_ = throttle.fail(ctx, "a@example.com", "", nil)
+		}
+		// failures that raced the locking one were counted after the reset
+		for i := 0; i < 3; i++ {
+			_, _ = throttle.cache.Incr(ctx, loginFailuresKey(domain.LockoutScopeAccount, "a@example.com"), time.Hour)
+		}
+
+		if err := throttle.fail(ctx, "a@example.com", "", nil); !errors.Is(err, domain.ErrTooManyAttempts) {
+			t.Fatalf("failure while locked: err = %v", err)
+		}
+		if lockouts.created != 1 {
+			t.Errorf("%d lockouts recorded while locked, want 1", lockouts.created)
+		}
+
+		// with the lock gone, the count still locks again
+		_ = throttle.cache.Delete(ctx, loginLockKey(domain.LockoutScopeAccount, "a@example.com"))
+		err := throttle.fail(ctx, "a@example.com", "", nil)
+		var locked *domain.LockedError
+		if !errors.As(err, &locked) {
+			t.Fatalf("failure without a lock: err = %v", err)
+		}
+		if lockouts.created != 2 || locked.RetryAfter != 2*time.Minute {
+			t.Errorf("relock: %d lockouts, retry after %s; want 2 and the second backoff", lockouts.created, locked.RetryAfter)
+		}
+	})
+}
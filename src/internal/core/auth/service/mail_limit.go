@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"time"
+)
+
+// Verification and password reset emails are limited per address and per
+// client within mailWindow, so that the endpoints cannot be used to flood
+// a mailbox or to send mail in bulk.
+const (
+	mailWindow         = time.Hour
+	mailLimitPerEmail  = 3
+	mailLimitPerClient = 20
+)
+
+// Kinds of mail counted by limitMail.
+const (
+	mailKindVerification  = "verification"
+	mailKindPasswordReset = "password_reset"
+)
+
+func mailRequestsKey(kind, scope, id string) string {
+	return "auth:mail_requests:" + kind + ":" + scope + ":" + id
+}
+
+// limitMail counts a request for a kind of mail to email and returns
+// ErrTooManyRequests once the address or the client asked too often.
+// Unknown addresses are counted like known ones so that the limit does not
+// reveal which emails are registered.
+func (s *authService) limitMail(ctx context.Context, kind, email string) error {
+	targets := []lockTarget{{scope: domain.LockoutScopeAccount, id: email, limit: mailLimitPerEmail}}
+	if ip := domain.ClientFromContext(ctx).IP; ip != "" {
+		targets = append(targets, lockTarget{scope: domain.LockoutScopeIP, id: ip, limit: mailLimitPerClient})
+	}
+
+	for _, target := range targets {
+		requests, err := s.cache.Incr(ctx, mailRequestsKey(kind, target.scope, target.id), mailWindow)
+		if err != nil {
+			return fmt.Errorf("failed to count mail requests: %w", err)
+		}
+		if requests > int64(target.limit) {
+			s.logger.WarnContext(ctx, "mail requests limited", "kind", kind, "scope", target.scope, "requests", requests)
+			return domain.ErrTooManyRequests
+		}
+	}
+	return nil
+}
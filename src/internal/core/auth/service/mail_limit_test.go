@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"testing"
+)
+
+func TestPasswordResetRequestsAreLimited(t *testing.T) {
+	client := func(ip string) context.Context {
+		return domain.ContextWithClient(context.Background(), domain.ClientInfo{IP: ip})
+	}
+
+	t.Run("per address", func(t *testing.T) {
+		s := newMFAService(t, 5)
+		for i := 1; i <= mailLimitPerEmail+1; i++ {
+			// a different client each time, so only the address counts
+			ctx := client(fmt.Sprintf("192.0.2.%d", i))
+			err := s.RequestPasswordReset(ctx, domain.PasswordResetRequestInput{Email: "nobody@example.com"})
+			if limited := errors.Is(err, domain.ErrTooManyRequests); limited != (i > mailLimitPerEmail) {
+				t.Fatalf("request %d: err = %v", i, err)
+			}
+		}
+		// the address is counted whatever its case
+		err := s.RequestPasswordReset(client("198.51.100.1"), domain.PasswordResetRequestInput{Email: "Nobody@Example.com"})
+		if !errors.Is(err, domain.ErrTooManyRequests) {
+			t.Errorf("differently cased address: err = %v", err)
+		}
+	})
+
+	t.Run("per client", func(t *testing.T) {
+		s := newMFAService(t, 5)
+		ctx := client("192.0.2.1")
+		for i := 1; i <= mailLimitPerClient+1; i++ {
+			email := fmt.Sprintf("user%d@example.com", i)
+			err := s.RequestPasswordReset(ctx, domain.PasswordResetRequestInput{Email: email})
+			if limited := errors.Is(err, domain.ErrTooManyRequests); limited != (i > mailLimitPerClient) {
+				t.Fatalf("request %d: err = %v", i, err)
+			}
+		}
+	})
+}
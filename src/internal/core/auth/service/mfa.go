@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/pkg/totp"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// mfaChallengeTTL is how long the second login step may take.
+	mfaChallengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many codes may be tried per challenge.
+	maxChallengeAttempts = 5
+	// totpSkew accepts codes from one step either side of now.
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// roles lists every role, in the order policies are reported.
+var roles = []string{domain.RoleCustomer, domain.RoleSeller, domain.RoleAdmin}
+
+func challengeAttemptsKey(tokenID string) string {
+	return "auth:mfa_challenge:" + tokenID
+}
+
+// CompleteMFALogin finishes a login started with a password, using either a
+// TOTP code or a recovery code.
+func (s *authService) CompleteMFALogin(ctx context.Context, input domain.MFALoginInput) (*domain.UserWithToken, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	claims, userID, err := s.parseToken(input.ChallengeToken, tokenTypeMFAChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	// the attempt is counted before the code is checked, so that requests
+	// racing on one challenge cannot try more than maxChallengeAttempts
+	key := challengeAttemptsKey(claims.ID)
+	attempts, err := s.cache.Incr(ctx, key, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record challenge attempt: %w", err)
+	}
+	if attempts > maxChallengeAttempts {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.throttle.checkMFA(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.enabledEnrollment(ctx, user.ID)
+	if errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.verifyMFACode(ctx, enrollment, input.Code, true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, s.mfaFailed(ctx, user)
+	}
+
+	// a used challenge is spent
+	if err := s.cache.Set(ctx, key, maxChallengeAttempts, time.Until(claims.ExpiresAt.Time)); err != nil {
+		return nil, fmt.Errorf("failed to spend challenge: %w", err)
+	}
+	if err := s.throttle.succeed(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserWithToken{User: user, Tokens: *tokens}, nil
+}
+
+// mfaFailed records a failed second factor and returns the error to report
+// for it.
+func (s *authService) mfaFailed(ctx context.Context, user *domain.User) error {
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventLoginFailed,
+		UserID:  &user.ID,
+		Details: domain.EventDetails{"reason": "invalid_mfa_code"},
+	})
+
+	err := s.throttle.failMFA(ctx, user.Email, domain.ClientFromContext(ctx).IP, &user.ID)
+	var locked *domain.LockedError
+	if errors.As(err, &locked) {
+		s.recordEvent(ctx, &domain.AuthEvent{
+			Type:    domain.EventLoginLocked,
+			UserID:  &user.ID,
+			Details: domain.EventDetails{"retry_after": locked.RetryAfter.String(), "scope": domain.LockoutScopeMFA},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrInvalidMFACode
+}
+
+// EnrollMFA starts enrollment with a fresh secret. Two-factor
+// authentication is not enabled until ActivateMFA confirms a code.
+func (s *authService) EnrollMFA(ctx context.Context, userID uint) (*domain.MFASetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Role != domain.RoleSeller && user.Role != domain.RoleAdmin {
+		return nil, domain.ErrMFANotAllowed
+	}
+
+	if enrollment, err := s.mfaRepo.GetEnrollment(ctx, userID); err == nil && enrollment.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get mfa enrollment: %w", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.SaveEnrollment(ctx, &domain.MFAEnrollment{UserID: userID, Secret: secret}); err != nil {
+		return nil, fmt.Errorf("failed to save mfa enrollment: %w", err)
+	}
+
+	return &domain.MFASetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.tokens.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ActivateMFA enables two-factor authentication once the user proves the
+// authenticator app works, and returns the first set of recovery codes.
+func (s *authService) ActivateMFA(ctx context.Context, userID uint, input domain.MFACodeInput) (*domain.RecoveryCodes, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.mfaRepo.GetEnrollment(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa enrollment: %w", err)
+	}
+	if enrollment.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	if err := s.checkMFACode(ctx, userID, enrollment, input.Code, false); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	enrollment.EnabledAt = &now
+	if err := s.mfaRepo.SaveEnrollment(ctx, enrollment); err != nil {
+		return nil, fmt.Errorf("failed to save mfa enrollment: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventMFAEnabled, UserID: &userID})
+
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+func (s *authService) DisableMFA(ctx context.Context, userID uint, input domain.DisableMFAInput) error {
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	required, err := s.mfaRequired(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFARequired
+	}
+
+	enrollment, err := s.enabledEnrollment(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkMFACode(ctx, userID, enrollment, input.Code, true); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteEnrollment(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa enrollment: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventMFADisabled, UserID: &userID})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID uint, input domain.MFACodeInput) (*domain.RecoveryCodes, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.enabledEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMFACode(ctx, userID, enrollment, input.Code, false); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventRecoveryCodesRegenerated, UserID: &userID})
+	return codes, nil
+}
+
+// ListMFAPolicies reports every role, including those never configured.
+func (s *authService) ListMFAPolicies(ctx context.Context) ([]*domain.MFARolePolicy, error) {
+	stored, err := s.mfaRepo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mfa policies: %w", err)
+	}
+
+	byRole := make(map[string]*domain.MFARolePolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	policies := make([]*domain.MFARolePolicy, 0, len(roles))
+	for _, role := range roles {
+		if policy, ok := byRole[role]; ok {
+			policies = append(policies, policy)
+		} else {
+			policies = append(policies, &domain.MFARolePolicy{Role: role})
+		}
+	}
+	return policies, nil
+}
+
+// SetMFAPolicy makes two-factor authentication required or optional for a
+// role. Members without it keep their sessions but cannot use their role
+// until they enroll and log in again.
+func (s *authService) SetMFAPolicy(ctx context.Context, role string, input domain.SetMFAPolicyInput) (*domain.MFARolePolicy, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+	if !isRole(role) {
+		return nil, domain.ErrUnknownRole
+	}
+
+	policy := &domain.MFARolePolicy{Role: role, Required: *input.Required, UpdatedAt: time.Now()}
+	if err := s.mfaRepo.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save mfa policy: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventMFAPolicyChanged,
+		Details: domain.EventDetails{"role": role, "required": strconv.FormatBool(policy.Required)},
+	})
+	return policy, nil
+}
+
+// mfaPending reports whether the user's role requires two-factor
+// authentication that the session has not passed.
+func (s *authService) mfaPending(ctx context.Context, user *domain.User, family *domain.TokenFamily) (bool, error) {
+	if family.MFAVerified {
+		return false, nil
+	}
+	return s.mfaRequired(ctx, user.Role)
+}
+
+func (s *authService) mfaRequired(ctx context.Context, role string) (bool, error) {
+	policy, err := s.mfaRepo.GetPolicy(ctx, role)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get mfa policy: %w", err)
+	}
+	return policy.Required, nil
+}
+
+func (s *authService) mfaEnabled(ctx context.Context, userID uint) (bool, error) {
+	_, err := s.enabledEnrollment(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnrolled) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *authService) enabledEnrollment(ctx context.Context, userID uint) (*domain.MFAEnrollment, error) {
+	enrollment, err := s.mfaRepo.GetEnrollment(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa enrollment: %w", err)
+	}
+	if enrollment.EnabledAt == nil {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	return enrollment, nil
+}
+
+// checkMFACode verifies a code a signed-in user gives to manage their
+// second factor. Wrong codes count towards the same lockout as at login, so
+// that these endpoints offer no way around it.
+func (s *authService) checkMFACode(ctx context.Context, userID uint, enrollment *domain.MFAEnrollment, code string, allowRecovery bool) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.throttle.checkMFA(ctx, user.Email); err != nil {
+		return err
+	}
+
+	ok, err := s.verifyMFACode(ctx, enrollment, code, allowRecovery)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.mfaFailed(ctx, user)
+	}
+	return nil
+}
+
+// verifyMFACode accepts a TOTP code once per time step and, if allowed, an
+// unused recovery code.
+func (s *authService) verifyMFACode(ctx context.Context, enrollment *domain.MFAEnrollment, code string, allowRecovery bool) (bool, error) {
+	if step, ok := totp.Validate(enrollment.Secret, code, time.Now(), totpSkew); ok {
+		fresh, err := s.mfaRepo.UseStep(ctx, enrollment.UserID, step)
+		if err != nil {
+			return false, fmt.Errorf("failed to record mfa code use: %w", err)
+		}
+		if fresh {
+			enrollment.LastUsedStep = step
+		}
+		return fresh, nil
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, enrollment.UserID, s.hashRecoveryCode(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return used, nil
+}
+
+func (s *authService) newRecoveryCodes(ctx context.Context, userID uint) (*domain.RecoveryCodes, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]*domain.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		codes[i] = code[:4] + "-" + code[4:]
+		records[i] = &domain.MFARecoveryCode{UserID: userID, CodeHash: s.hashRecoveryCode(code)}
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return &domain.RecoveryCodes{Codes: codes}, nil
+}
+
+// hashRecoveryCode keys the hash of a normalized recovery code, as its 40
+// bits would not hold out against guessing from a leaked table otherwise.
+func (s *authService) hashRecoveryCode(code string) string {
+	mac := hmac.New(sha256.New, s.recoveryKey)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// recoveryCodeKey derives the key for recovery code hashes from the token
+// secret, under a label of its own.
+func recoveryCodeKey(secret []byte) []byte {
+	// hkdf only fails for keys longer than 255 hashes
+	key, _ := hkdf.Key(sha256.New, secret, nil, "mfa recovery codes", sha256.Size)
+	return key
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+func isRole(role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
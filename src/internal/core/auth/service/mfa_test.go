@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"golang_marketplace/src/pkg/totp"
+	"gorm.io/gorm"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPassword = "correct horse battery"
+
+type stubUsers struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r *stubUsers) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	if email != r.user.Email {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *stubUsers) GetByID(_ context.Context, id uint) (*domain.User, error) {
+	if id != r.user.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+type stubMFA struct {
+	domain.MFARepository
+	enrollment *domain.MFAEnrollment
+}
+
+func (r *stubMFA) GetEnrollment(context.Context, uint) (*domain.MFAEnrollment, error) {
+	return r.enrollment, nil
+}
+
+func (r *stubMFA) UseStep(context.Context, uint, int64) (bool, error) {
+	return true, nil
+}
+
+func (r *stubMFA) UseRecoveryCode(context.Context, uint, string) (bool, error) {
+	return false, nil
+}
+
+type stubLockouts struct {
+	domain.LockoutRepository
+}
+
+func (stubLockouts) Create(context.Context, *domain.LockoutEvent) error {
+	return nil
+}
+
+type stubEvents struct {
+	domain.AuthEventRepository
+}
+
+func (stubEvents) Create(context.Context, *domain.AuthEvent) error {
+	return nil
+}
+
+func newMFAService(t *testing.T, maxAttempts int) *authService {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := time.Now()
+
+	return NewAuthService(
+		&stubUsers{user: &domain.User{ID: 1, Email: "seller@example.com", PasswordHash: string(hash), Role: domain.RoleSeller}},
+		nil, nil, nil,
+		&stubMFA{enrollment: &domain.MFAEnrollment{UserID: 1, Secret: secret, EnabledAt: &enabled}},
+		stubLockouts{},
+		stubEvents{},
+		cache.NewMemoryCache(1000),
+		nil,
+		TokenOptions{Secret: []byte("a-secret-that-is-long-enough-for-tests"), Issuer: "test", AccessTokenTTL: time.Minute},
+		AccountOptions{},
+		LockoutOptions{
+			MaxAttempts:   maxAttempts,
+			IPMaxAttempts: maxAttempts,
+			Window:        time.Hour,
+			Duration:      time.Minute,
+			MaxDuration:   time.Hour,
+		},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	).(*authService)
+}
+
+func challenge(t *testing.T, s *authService) string {
+	t.Helper()
+	result, err := s.Login(context.Background(), "seller@example.com", testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.MFARequired {
+		t.Fatal("login did not ask for a second factor")
+	}
+	return result.ChallengeToken
+}
+
+func TestChallengeAttemptsAreCounted(t *testing.T) {
+	s := newMFAService(t, 100)
+	token := challenge(t, s)
+
+	// concurrent guesses must not get past the limit between reading and
+	// writing the counter
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	tried := 0
+	for i := 0; i < 4*maxChallengeAttempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CompleteMFALogin(context.Background(), domain.MFALoginInput{ChallengeToken: token, Code: "000000"})
+			if errors.Is(err, domain.ErrInvalidMFACode) {
+				mu.Lock()
+				tried++
+				mu.Unlock()
+			} else if !errors.Is(err, domain.ErrInvalidToken) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tried != maxChallengeAttempts {
+		t.Errorf("%d codes were checked, want %d", tried, maxChallengeAttempts)
+	}
+}
+
+func TestMFAFailuresOutliveChallenges(t *testing.T) {
+	ctx := context.Background()
+	s := newMFAService(t, 3)
+
+	tests := []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "first wrong code", wantErr: domain.ErrInvalidMFACode},
+		{name: "second wrong code", wantErr: domain.ErrInvalidMFACode},
+		{name: "third wrong code locks", wantErr: domain.ErrTooManyAttempts},
+	}
+
+	for _, tt := range tests {
+		// every attempt gets a fresh challenge
+		token := challenge(t, s)
+		_, err := s.CompleteMFALogin(ctx, domain.MFALoginInput{ChallengeToken: token, Code: "000000"})
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := s.Login(ctx, "seller@example.com", testPassword); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("login while the second factor is locked: err = %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+}
+
+func TestPasswordAloneKeepsFailedLogins(t *testing.T) {
+	ctx := context.Background()
+	s := newMFAService(t, 3)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Login(ctx, "seller@example.com", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("err = %v, want %v", err, domain.ErrInvalidCredentials)
+		}
+	}
+	// the right password without the second factor does not clear them
+	challenge(t, s)
+
+	if _, err := s.Login(ctx, "seller@example.com", "wrong"); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("err = %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+}
+
+func TestMFAManagementCountsFailures(t *testing.T) {
+	ctx := context.Background()
+	s := newMFAService(t, 3)
+
+	for i, want := range []error{domain.ErrInvalidMFACode, domain.ErrInvalidMFACode, domain.ErrTooManyAttempts} {
+		_, err := s.RegenerateRecoveryCodes(ctx, 1, domain.MFACodeInput{Code: "000000"})
+		if !errors.Is(err, want) {
+			t.Fatalf("attempt %d: err = %v, want %v", i+1, err, want)
+		}
+	}
+
+	// the lockout is the one guarding login
+	if _, err := s.Login(ctx, "seller@example.com", testPassword); !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Errorf("login after wrong codes: err = %v, want %v", err, domain.ErrTooManyAttempts)
+	}
+}
+
+func TestRecoveryCodeHashesAreKeyed(t *testing.T) {
+	s := newMFAService(t, 3)
+	other := newMFAService(t, 3)
+	other.recoveryKey = recoveryCodeKey([]byte("another-secret-that-is-long-enough"))
+
+	code := normalizeRecoveryCode("ABCD-EFGH")
+	if s.hashRecoveryCode(code) != s.hashRecoveryCode("abcdefgh") {
+		t.Error("normalized codes hash differently")
+	}
+	if s.hashRecoveryCode(code) == hashToken(code) {
+		t.Error("recovery code hash is a plain hash")
+	}
+	if s.hashRecoveryCode(code) == other.hashRecoveryCode(code) {
+		t.Error("recovery code hash does not depend on the key")
+	}
+}
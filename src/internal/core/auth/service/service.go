@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"golang_marketplace/src/internal/platform/mailer"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// dummyHash is compared against when the email is unknown so that a failed
+// login takes the same time whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type authService struct {
+	userRepo      domain.UserRepository
+	refreshRepo   domain.RefreshTokenRepository
+	apiKeyRepo    domain.APIKeyRepository
+	userTokenRepo domain.UserTokenRepository
+	mfaRepo       domain.MFARepository
+	lockoutRepo   domain.LockoutRepository
+	eventRepo     domain.AuthEventRepository
+	cache         cache.Cache
+	denylist      *denylist
+	throttle      *loginThrottle
+	mailer        mailer.Mailer
+	tokens        TokenOptions
+	account       AccountOptions
+	recoveryKey   []byte
+	logger        *slog.Logger
+}
+
+func NewAuthService(
+	userRepo domain.UserRepository,
+	refreshRepo domain.RefreshTokenRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	userTokenRepo domain.UserTokenRepository,
+	mfaRepo domain.MFARepository,
+	lockoutRepo domain.LockoutRepository,
+	eventRepo domain.AuthEventRepository,
+	store cache.Cache,
+	sender mailer.Mailer,
+	tokens TokenOptions,
+	account AccountOptions,
+	lockout LockoutOptions,
+	logger *slog.Logger,
+) domain.AuthService {
+	return &authService{
+		userRepo:      userRepo,
+		refreshRepo:   refreshRepo,
+		apiKeyRepo:    apiKeyRepo,
+		userTokenRepo: userTokenRepo,
+		mfaRepo:       mfaRepo,
+		lockoutRepo:   lockoutRepo,
+		eventRepo:     eventRepo,
+		cache:         store,
+		denylist:      &denylist{cache: store, accessTTL: tokens.AccessTokenTTL},
+		throttle:      &loginThrottle{cache: store, repo: lockoutRepo, opts: lockout, logger: logger},
+		mailer:        sender,
+		tokens:        tokens,
+		account:       account,
+		recoveryKey:   recoveryCodeKey(tokens.Secret),
+		logger:        logger,
+	}
+}
+
+func (s *authService) Register(ctx context.Context, input domain.RegisterInput) (*domain.UserWithToken, error) {
+	input.Email = normalizeEmail(input.Email)
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	// email must be unique
+	if _, err := s.userRepo.GetByEmail(ctx, input.Email); err == nil {
+		return nil, domain.ErrEmailTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check if email exists: %w", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &domain.User{
+		Email:        input.Email,
+		PasswordHash: string(hash),
+		FirstName:    strings.TrimSpace(input.FirstName),
+		LastName:     strings.TrimSpace(input.LastName),
+		Role:         domain.RoleCustomer,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, domain.ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventRegistered, UserID: &user.ID})
+
+	// the account works without it; the user can ask for a new email
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		s.logger.WarnContext(ctx, "failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
+	tokens, err := s.startSession(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserWithToken{User: user, Tokens: *tokens}, nil
+}
+
+// Login checks the password. Accounts with two-factor authentication get a
+// challenge token instead of tokens and finish with CompleteMFALogin.
+// Repeated failures lock the account or the client address for a while.
+func (s *authService) Login(ctx context.Context, email, password string) (*domain.LoginResult, error) {
+	email = normalizeEmail(email)
+	ip := domain.ClientFromContext(ctx).IP
+
+	if err := s.throttle.check(ctx, email, ip); err != nil {
+		if errors.Is(err, domain.ErrTooManyAttempts) {
+			s.recordEvent(ctx, &domain.AuthEvent{
+				Type:    domain.EventLoginFailed,
+				Email:   email,
+				Details: domain.EventDetails{"reason": "locked"},
+			})
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, s.loginFailed(ctx, email, ip, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, s.loginFailed(ctx, email, ip, &user.ID)
+	}
+
+	enrolled, err := s.mfaEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// failed logins are only cleared once the second factor is through, so
+	// that a known password does not buy a fresh budget of codes
+	if enrolled {
+		if err := s.throttle.checkMFA(ctx, email); err != nil {
+			return nil, err
+		}
+		challenge, err := s.signChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{
+			MFARequired:    true,
+			ChallengeToken: challenge,
+			ExpiresIn:      int64(mfaChallengeTTL.Seconds()),
+		}, nil
+	}
+
+	if err := s.throttle.succeed(ctx, email); err != nil {
+		return nil, err
+	}
+	tokens, err := s.startSession(ctx, user, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// RefreshToken rotates a refresh token: the presented token is spent and a
+// new one in the same family is returned. Presenting a spent token again
+// means it was copied, so the whole family is revoked.
+func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	current, err := s.refreshRepo.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	family, err := s.refreshRepo.GetFamily(ctx, current.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token family: %w", err)
+	}
+	if family.RevokedAt != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if current.UsedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, family)
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// reload the user so a changed role or deleted account takes effect
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	mfaPending, err := s.mfaPending(ctx, user, family)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.signAccessToken(user, family.ID, mfaPending)
+	if err != nil {
+		return nil, err
+	}
+	nextToken, next, err := s.newRefreshToken(user.ID, family.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.refreshRepo.Rotate(ctx, current.ID, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// another request spent the same token first
+		return nil, s.revokeReusedFamily(ctx, family)
+	}
+
+	// the session list is informational; a failed write must not end it
+	if err := s.refreshRepo.TouchFamily(ctx, family.ID, clientInfo(ctx), time.Now()); err != nil {
+		s.logger.WarnContext(ctx, "failed to record session use", "session_id", family.ID, "error", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventTokenRefreshed, UserID: &user.ID, SessionID: &family.ID})
+
+	return s.tokenPair(accessToken, nextToken), nil
+}
+
+func (s *authService) revokeReusedFamily(ctx context.Context, family *domain.TokenFamily) error {
+	if err := s.endSession(ctx, family.ID, domain.RevokeReasonReuse); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventTokenReused, UserID: &family.UserID, SessionID: &family.ID})
+	return domain.ErrTokenReused
+}
+
+func (s *authService) ValidateToken(ctx context.Context, token string) (*domain.Claims, error) {
+	claims, userID, err := s.parseToken(token, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionID *uuid.UUID
+	if claims.SessionID != "" {
+		id, err := uuid.Parse(claims.SessionID)
+		if err != nil {
+			return nil, domain.ErrInvalidToken
+		}
+		sessionID = &id
+	}
+
+	denied, err := s.denylist.isDenied(ctx, claims.ID, sessionID, userID, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if denied {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return &domain.Claims{
+		TokenID:       claims.ID,
+		UserID:        userID,
+		Email:         claims.Email,
+		Role:          claims.Role,
+		EmailVerified: claims.EmailVerified,
+		MFAPending:    claims.MFAPending,
+		SessionID:     sessionID,
+		IssuedAt:      claims.IssuedAt.Time,
+		ExpiresAt:     claims.ExpiresAt.Time,
+	}, nil
+}
+
+// Logout denies the caller's access token and, when a refresh token is
+// given, revokes the session it belongs to.
+func (s *authService) Logout(ctx context.Context, claims *domain.Claims, refreshToken string) error {
+	if err := s.denylist.denyToken(ctx, claims.TokenID, claims.ExpiresAt); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventLoggedOut, UserID: &claims.UserID})
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	current, err := s.refreshRepo.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if current.UserID != claims.UserID {
+		return domain.ErrInvalidToken
+	}
+
+	return s.endSession(ctx, current.FamilyID, domain.RevokeReasonLogout)
+}
+
+// ChangePassword replaces the user's password and ends every session,
+// including access tokens that have not expired yet.
+func (s *authService) ChangePassword(ctx context.Context, userID uint, input domain.ChangePasswordInput) error {
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hash)
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventPasswordChanged, UserID: &user.ID})
+
+	return s.revokeUserSessions(ctx, user.ID, domain.RevokeReasonPasswordChange)
+}
+
+func (s *authService) revokeUserSessions(ctx context.Context, userID uint, reason string) error {
+	if err := s.refreshRepo.RevokeUserFamilies(ctx, userID, reason); err != nil {
+		return fmt.Errorf("failed to revoke token families: %w", err)
+	}
+	return s.denylist.denyUserTokens(ctx, userID, time.Now())
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
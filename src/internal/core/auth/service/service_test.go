@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memRefreshTokens keeps token families in memory, rotating tokens the way
+// the repository does.
+type memRefreshTokens struct {
+	domain.RefreshTokenRepository
+	mu       sync.Mutex
+	families map[uuid.UUID]*domain.TokenFamily
+	tokens   map[string]*domain.RefreshToken
+}
+
+func newMemRefreshTokens() *memRefreshTokens {
+	return &memRefreshTokens{
+		families: make(map[uuid.UUID]*domain.TokenFamily),
+		tokens:   make(map[string]*domain.RefreshToken),
+	}
+}
+
+func (r *memRefreshTokens) CreateFamily(_ context.Context, family *domain.TokenFamily) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	family.ID = uuid.New()
+	r.families[family.ID] = family
+	return nil
+}
+
+func (r *memRefreshTokens) GetFamily(_ context.Context, id uuid.UUID) (*domain.TokenFamily, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	family := *r.families[id]
+	return &family, nil
+}
+
+func (r *memRefreshTokens) RevokeFamily(_ context.Context, id uuid.UUID, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.families[id].RevokedAt = &now
+	r.families[id].RevokedReason = reason
+	return nil
+}
+
+func (r *memRefreshTokens) RevokeUserFamilies(_ context.Context, userID uint, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, family := range r.families {
+		if family.UserID == userID && family.RevokedAt == nil {
+			family.RevokedAt = &now
+			family.RevokedReason = reason
+		}
+	}
+	return nil
+}
+
+func (r *memRefreshTokens) TouchFamily(context.Context, uuid.UUID, domain.ClientInfo, time.Time) error {
+	return nil
+}
+
+func (r *memRefreshTokens) Create(_ context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uuid.New()
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *memRefreshTokens) GetByHash(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *memRefreshTokens) Rotate(_ context.Context, oldID uuid.UUID, next *domain.RefreshToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.ID != oldID {
+			continue
+		}
+		if token.UsedAt != nil {
+			return false, nil
+		}
+		now := time.Now()
+		token.UsedAt = &now
+		next.ID = uuid.New()
+		r.tokens[next.TokenHash] = next
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *stubUsers) Update(context.Context, *domain.User) error {
+	return nil
+}
+
+func newSessionService(t *testing.T) *authService {
+	t.Helper()
+	s := newMFAService(t, 5)
+	s.refreshRepo = newMemRefreshTokens()
+	s.tokens.RefreshTokenTTL = time.Hour
+	return s
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionService(t)
+
+	first, err := s.startSession(ctx, s.userRepo.(*stubUsers).user, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.RefreshToken(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// presenting the rotated token again means it leaked
+	if _, err := s.RefreshToken(ctx, first.RefreshToken); !errors.Is(err, domain.ErrTokenReused) {
+		t.Fatalf("reused refresh token: err = %v, want %v", err, domain.ErrTokenReused)
+	}
+	if _, err := s.RefreshToken(ctx, second.RefreshToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("latest refresh token of the family: err = %v, want %v", err, domain.ErrInvalidToken)
+	}
+	if _, err := s.ValidateToken(ctx, second.AccessToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Errorf("access token of the family: err = %v, want %v", err, domain.ErrInvalidToken)
+	}
+}
+
+func TestValidateTokenRejectsRevokedAccessTokens(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		revoke func(s *authService, claims *domain.Claims) error
+	}{
+		{
+			name: "logout",
+			revoke: func(s *authService, claims *domain.Claims) error {
+				return s.Logout(ctx, claims, "")
+			},
+		},
+		{
+			name: "password change",
+			revoke: func(s *authService, claims *domain.Claims) error {
+				return s.ChangePassword(ctx, claims.UserID, domain.ChangePasswordInput{
+					CurrentPassword: testPassword,
+					NewPassword:     "another horse battery",
+				})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSessionService(t)
+			user := s.userRepo.(*stubUsers).user
+
+			pair, err := s.startSession(ctx, user, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := s.ValidateToken(ctx, pair.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.revoke(s, claims); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.ValidateToken(ctx, pair.AccessToken); !errors.Is(err, domain.ErrInvalidToken) {
+				t.Errorf("revoked access token: err = %v, want %v", err, domain.ErrInvalidToken)
+			}
+
+			// issue times count to the millisecond, so a login right after
+			// the revocation is not caught by it
+			time.Sleep(2 * time.Millisecond)
+			fresh, err := s.startSession(ctx, user, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.ValidateToken(ctx, fresh.AccessToken); err != nil {
+				t.Errorf("access token issued after the revocation: err = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// maxUserAgentLength matches the token_families.user_agent column.
+const maxUserAgentLength = 512
+
+// clientInfo returns the request's client, trimmed to fit a session record.
+func clientInfo(ctx context.Context) domain.ClientInfo {
+	client := domain.ClientFromContext(ctx)
+	if len(client.UserAgent) > maxUserAgentLength {
+		client.UserAgent = client.UserAgent[:maxUserAgentLength]
+	}
+	return client
+}
+
+// endSession revokes a token family and the access tokens issued for it.
+func (s *authService) endSession(ctx context.Context, familyID uuid.UUID, reason string) error {
+	if err := s.refreshRepo.RevokeFamily(ctx, familyID, reason); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return s.denylist.denySession(ctx, familyID)
+}
+
+// ListSessions returns the caller's sessions that can still be refreshed.
+func (s *authService) ListSessions(ctx context.Context, claims *domain.Claims) ([]*domain.Session, error) {
+	// every refresh extends a family by the refresh token lifetime
+	usedSince := time.Now().Add(-s.tokens.RefreshTokenTTL)
+	families, err := s.refreshRepo.ListActiveFamilies(ctx, claims.UserID, usedSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*domain.Session, len(families))
+	for i, family := range families {
+		sessions[i] = &domain.Session{
+			ID:         family.ID,
+			UserAgent:  family.UserAgent,
+			IP:         family.IP,
+			CreatedAt:  family.CreatedAt,
+			LastUsedAt: family.LastUsedAt,
+			Current:    claims.SessionID != nil && *claims.SessionID == family.ID,
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's sessions out, including access
+// tokens already issued for it.
+func (s *authService) RevokeSession(ctx context.Context, userID uint, id uuid.UUID) error {
+	err := s.refreshRepo.RevokeUserFamily(ctx, userID, id, domain.RevokeReasonSignedOut)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	if err := s.denylist.denySession(ctx, id); err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{Type: domain.EventSessionRevoked, UserID: &userID, SessionID: &id})
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere, including the session
+// making the request.
+func (s *authService) RevokeAllSessions(ctx context.Context, userID uint) error {
+	if err := s.revokeUserSessions(ctx, userID, domain.RevokeReasonSignedOut); err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventSessionsRevoked,
+		UserID:  &userID,
+		Details: domain.EventDetails{"reason": domain.RevokeReasonSignedOut},
+	})
+	return nil
+}
+
+func (s *authService) RevokeUserAccess(ctx context.Context, userID uint, reason string) error {
+	if err := s.revokeUserSessions(ctx, userID, reason); err != nil {
+		return err
+	}
+	revoked, err := s.apiKeyRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventSessionsRevoked,
+		UserID:  &userID,
+		Details: domain.EventDetails{"reason": reason, "api_keys_revoked": strconv.FormatInt(revoked, 10)},
+	})
+	return nil
+}
+
+// TerminateUserSessions lets an admin sign a compromised account out
+// everywhere.
+func (s *authService) TerminateUserSessions(ctx context.Context, adminID, userID uint) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrUserNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.revokeUserSessions(ctx, userID, domain.RevokeReasonAdmin); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:    domain.EventSessionsRevoked,
+		UserID:  &userID,
+		Details: domain.EventDetails{"reason": domain.RevokeReasonAdmin},
+	})
+
+	s.logger.InfoContext(ctx, "sessions terminated", "user_id", userID, "admin_id", adminID)
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/auth/domain"
+	"strconv"
+	"time"
+)
+
+const (
+	tokenTypeAccess       = "access"
+	tokenTypeMFAChallenge = "mfa_challenge"
+)
+
+func init() {
+	// issue times are compared with revocations to the millisecond; in whole
+	// seconds, a token issued just before a password change would pass
+	jwt.TimePrecision = time.Millisecond
+}
+
+// TokenOptions configures how access and refresh tokens are issued.
+type TokenOptions struct {
+	Secret          []byte
+	Issuer          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+type tokenClaims struct {
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	MFAPending    bool   `json:"mfa_pending,omitempty"`
+	SessionID     string `json:"sid,omitempty"`
+	TokenType     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// issueTokenPair signs an access token and stores a new refresh token in the
+// given family.
+func (s *authService) issueTokenPair(ctx context.Context, user *domain.User, family *domain.TokenFamily) (*domain.TokenPair, error) {
+	mfaPending, err := s.mfaPending(ctx, user, family)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.signAccessToken(user, family.ID, mfaPending)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, record, err := s.newRefreshToken(user.ID, family.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return s.tokenPair(accessToken, refreshToken), nil
+}
+
+// startSession opens a new token family for a fresh login. mfaVerified
+// records whether the login passed a second factor.
+func (s *authService) startSession(ctx context.Context, user *domain.User, mfaVerified bool) (*domain.TokenPair, error) {
+	client := clientInfo(ctx)
+	family := &domain.TokenFamily{
+		UserID:      user.ID,
+		UserAgent:   client.UserAgent,
+		IP:          client.IP,
+		LastUsedAt:  time.Now(),
+		MFAVerified: mfaVerified,
+	}
+	if err := s.refreshRepo.CreateFamily(ctx, family); err != nil {
+		return nil, fmt.Errorf("failed to create token family: %w", err)
+	}
+	s.recordEvent(ctx, &domain.AuthEvent{
+		Type:      domain.EventLoginSucceeded,
+		UserID:    &user.ID,
+		SessionID: &family.ID,
+		Details:   domain.EventDetails{"mfa": strconv.FormatBool(mfaVerified)},
+	})
+
+	return s.issueTokenPair(ctx, user, family)
+}
+
+func (s *authService) tokenPair(accessToken, refreshToken string) *domain.TokenPair {
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokens.AccessTokenTTL.Seconds()),
+	}
+}
+
+func (s *authService) signAccessToken(user *domain.User, sessionID uuid.UUID, mfaPending bool) (string, error) {
+	return s.signToken(tokenClaims{
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		MFAPending:    mfaPending,
+		SessionID:     sessionID.String(),
+		TokenType:     tokenTypeAccess,
+	}, user.ID, s.tokens.AccessTokenTTL)
+}
+
+// signChallenge issues the token that stands in for a password between the
+// two steps of a two-factor login.
+func (s *authService) signChallenge(user *domain.User) (string, error) {
+	return s.signToken(tokenClaims{TokenType: tokenTypeMFAChallenge}, user.ID, mfaChallengeTTL)
+}
+
+func (s *authService) signToken(claims tokenClaims, userID uint, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Issuer:    s.tokens.Issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.tokens.Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// parseToken verifies the signature, expiry, issuer and type of a token and
+// returns its claims.
+func (s *authService) parseToken(token, tokenType string) (*tokenClaims, uint, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return s.tokens.Secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.tokens.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, 0, domain.ErrInvalidToken
+	}
+
+	if claims.TokenType != tokenType || claims.ID == "" || claims.IssuedAt == nil {
+		return nil, 0, domain.ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, 0, domain.ErrInvalidToken
+	}
+
+	return &claims, uint(userID), nil
+}
+
+// newRefreshToken returns an opaque refresh token and the record to store
+// for it. Only the hash is stored, so a database leak does not leak tokens.
+func (s *authService) newRefreshToken(userID uint, familyID uuid.UUID) (string, *domain.RefreshToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	return token, &domain.RefreshToken{
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.tokens.RefreshTokenTTL),
+	}, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
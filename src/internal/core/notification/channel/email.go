@@ -0,0 +1,50 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/internal/platform/mailer"
+)
+
+const emailTemplate = `Hello %s,
+
+%s
+%s
+You can choose which notifications you get by email in your notification settings.
+`
+
+type emailChannel struct {
+	mailer mailer.Mailer
+}
+
+// NewEmail returns a channel that mails notifications to the user's
+// account address.
+func NewEmail(mailer mailer.Mailer) domain.Channel {
+	return &emailChannel{mailer: mailer}
+}
+
+func (c *emailChannel) Name() string {
+	return domain.ChannelEmail
+}
+
+func (c *emailChannel) Send(ctx context.Context, recipient *domain.Recipient, notification *domain.Notification) error {
+	name := recipient.FirstName
+	if name == "" {
+		name = recipient.Email
+	}
+	link := ""
+	if notification.Link != "" {
+		link = "\n" + notification.Link + "\n"
+	}
+
+	err := c.mailer.Send(ctx, mailer.Message{
+		To:      []string{recipient.Email},
+		Subject: notification.Title,
+		Body:    fmt.Sprintf(emailTemplate, name, notification.Body, link),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"gorm.io/gorm"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every webhook request.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookTimeout bounds a single webhook request.
+const webhookTimeout = 10 * time.Second
+
+// ErrAddressNotAllowed is returned for webhooks whose host resolves to an
+// address that is not public, such as loopback, the private ranges or the
+// link-local range holding cloud metadata services.
+var ErrAddressNotAllowed = errors.New("webhook address is not allowed")
+
+// reservedPrefixes are not public either but are not caught by the netip
+// predicates: "this network" and the carrier-grade NAT range.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+type webhookChannel struct {
+	webhooks domain.WebhookRepository
+	client   *http.Client
+}
+
+// NewWebhook returns a channel that posts notifications as JSON to the URL
+// the user set. Users without a webhook are skipped.
+//
+// The signature header holds "sha256=" and the hex HMAC-SHA256 of the
+// timestamp header, a dot and the body, keyed with the webhook secret.
+// Receivers should check it and reject old timestamps.
+func NewWebhook(webhooks domain.WebhookRepository) domain.Channel {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would make the connection on our behalf, past the address check
+	transport.Proxy = nil
+	transport.DialContext = publicDialer(&net.Dialer{Timeout: webhookTimeout})
+
+	return &webhookChannel{
+		webhooks: webhooks,
+		client: &http.Client{
+			Timeout:   webhookTimeout,
+			Transport: transport,
+			// a redirect could point the request somewhere the user did not choose
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (c *webhookChannel) Name() string {
+	return domain.ChannelWebhook
+}
+
+func (c *webhookChannel) Send(ctx context.Context, recipient *domain.Recipient, notification *domain.Notification) error {
+	webhook, err := c.webhooks.GetByUserID(ctx, recipient.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	// webhooks set before https was required are skipped too
+	if target, err := url.Parse(webhook.URL); err != nil || target.Scheme != "https" {
+		return errors.New("webhook url must use https")
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, notification.Type)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+sign(webhook.Secret, timestamp, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// publicDialer resolves the host itself and only connects to public
+// addresses. It dials the address it checked rather than the name, so that
+// a second lookup cannot answer differently.
+func publicDialer(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if !publicAddress(ip) {
+				return nil, fmt.Errorf("%w: %s resolves to %s", ErrAddressNotAllowed, host, ip)
+			}
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+)
+
+type stubWebhooks struct {
+	domain.WebhookRepository
+	webhook *domain.Webhook
+}
+
+func (r *stubWebhooks) GetByUserID(context.Context, uint) (*domain.Webhook, error) {
+	return r.webhook, nil
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := publicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("publicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookRefusesInternalHosts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "loopback over https", url: server.URL, wantErr: ErrAddressNotAllowed},
+		{name: "localhost by name", url: "https://localhost/hook", wantErr: ErrAddressNotAllowed},
+		{name: "metadata service", url: "https://169.254.169.254/latest/meta-data/", wantErr: ErrAddressNotAllowed},
+		{name: "plain http", url: "http://93.184.216.34/hook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhooks := &stubWebhooks{webhook: &domain.Webhook{UserID: 1, URL: tt.url, Secret: "secret"}}
+			err := NewWebhook(webhooks).Send(context.Background(), &domain.Recipient{ID: 1}, &domain.Notification{Type: "test"})
+			if err == nil {
+				t.Fatal("expected the webhook to be refused")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if n := calls.Load(); n != 0 {
+		t.Errorf("server was called %d times", n)
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "golang_marketplace/src/internal/core/notification/domain"
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type NotificationListResponse struct {
+	Notifications []*domain.Notification `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+}
+
+type UnreadCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+type PreferenceListResponse struct {
+	Preferences []*domain.Preference `json:"preferences"`
+}
+
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
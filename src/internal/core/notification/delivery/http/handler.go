@@ -0,0 +1,285 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/notification/delivery/dto"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/pkg/validator"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type NotificationHandler struct {
+	service domain.NotificationService
+	logger  *slog.Logger
+}
+
+func NewNotificationHandler(service domain.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListNotifications godoc
+// @Summary List notifications
+// @Description List the caller's inbox, newest first
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param type query string false "Notification type"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.NotificationListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	filter := domain.NotificationFilter{
+		UserID: claims.UserID,
+		Type:   c.Query("type"),
+		Page:   1,
+		Limit:  20,
+	}
+
+	if unread, err := strconv.ParseBool(c.Query("unread")); err == nil {
+		filter.Unread = unread
+	}
+
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			filter.Page = page
+		}
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			filter.Limit = limit
+		}
+	}
+
+	notifications, total, err := h.service.ListNotifications(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, "failed to list notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		Page:          filter.Page,
+		Limit:         filter.Limit,
+	})
+}
+
+// UnreadCount godoc
+// @Summary Count unread notifications
+// @Description Count the notifications in the caller's inbox that are not read yet
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UnreadCountResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	count, err := h.service.UnreadCount(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to count unread notifications", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UnreadCountResponse{Count: count})
+}
+
+// MarkRead godoc
+// @Summary Mark a notification read
+// @Description Mark one of the caller's notifications read. Marking it again keeps the first time it was read.
+// @Tags notifications
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid notification ID"})
+		return
+	}
+
+	if err := h.service.MarkRead(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to mark notification read", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Description Mark every unread notification in the caller's inbox read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MarkAllReadResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications/read [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	marked, err := h.service.MarkAllRead(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to mark notifications read", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MarkAllReadResponse{Marked: marked})
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Show, for every type of notification, which channels the caller gets it on
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PreferenceListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	preferences, err := h.service.GetPreferences(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to get notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PreferenceListResponse{Preferences: preferences})
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Turn channels on or off per type of notification. Preferences not listed are left as they are.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body domain.UpdatePreferencesInput true "Preferences to change"
+// @Success 200 {object} dto.PreferenceListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.UpdatePreferencesInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to update notification preferences", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PreferenceListResponse{Preferences: preferences})
+}
+
+// GetWebhook godoc
+// @Summary Get the notification webhook
+// @Description Show where the caller's notifications are posted. The secret is only shown when the webhook is set.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.Webhook
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /notifications/webhook [get]
+func (h *NotificationHandler) GetWebhook(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	webhook, err := h.service.GetWebhook(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to get webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// SetWebhook godoc
+// @Summary Set the notification webhook
+// @Description Post the caller's notifications to a URL. Setting it again replaces the URL and the signing secret.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body domain.WebhookInput true "Webhook URL"
+// @Success 200 {object} domain.CreatedWebhook
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /notifications/webhook [put]
+func (h *NotificationHandler) SetWebhook(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.WebhookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	webhook, err := h.service.SetWebhook(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to set webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary Remove the notification webhook
+// @Description Stop posting the caller's notifications to their webhook
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /notifications/webhook [delete]
+func (h *NotificationHandler) DeleteWebhook(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), claims.UserID); err != nil {
+		h.handleError(c, "failed to delete webhook", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) handleError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, validator.ErrValidation), errors.Is(err, domain.ErrUnknownEventType),
+		errors.Is(err, domain.ErrUnknownChannel):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrNotificationNotFound), errors.Is(err, domain.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), msg, "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	authhttp "golang_marketplace/src/internal/core/auth/delivery/http"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"log/slog"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, service domain.NotificationService, authenticate gin.HandlerFunc, logger *slog.Logger) {
+	handler := NewNotificationHandler(service, logger)
+
+	// notifications are for the person, not for integrations acting with an API key
+	notifications := router.Group("/notifications", authenticate, authhttp.RequireSession())
+	{
+		notifications.GET("", handler.ListNotifications)
+		notifications.GET("/unread-count", handler.UnreadCount)
+		notifications.POST("/read", handler.MarkAllRead)
+		notifications.POST("/:id/read", handler.MarkRead)
+
+		notifications.GET("/preferences", handler.GetPreferences)
+		notifications.PUT("/preferences", handler.UpdatePreferences)
+
+		notifications.GET("/webhook", handler.GetWebhook)
+		notifications.PUT("/webhook", handler.SetWebhook)
+		notifications.DELETE("/webhook", handler.DeleteWebhook)
+	}
+}
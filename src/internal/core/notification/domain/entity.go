@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Event types other modules notify users about. Preferences are kept per
+// type, so a new kind of notification is added here first.
+const (
+	EventOrderStatusChanged = "order.status_changed"
+	EventBackInStock        = "product.back_in_stock"
+	EventPriceDropped       = "product.price_dropped"
+	EventReviewReceived     = "review.received"
+	EventPayoutSent         = "payout.sent"
+	EventDataExportReady    = "account.data_export_ready"
+)
+
+var EventTypes = []string{
+	EventOrderStatusChanged,
+	EventBackInStock,
+	EventPriceDropped,
+	EventReviewReceived,
+	EventPayoutSent,
+	EventDataExportReady,
+}
+
+// ChannelInApp is the inbox. Other channels are registered by name, see
+// Channel.
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrUnknownEventType     = errors.New("unknown notification type")
+	ErrUnknownChannel       = errors.New("unknown notification channel")
+	ErrWebhookNotFound      = errors.New("webhook not set")
+	ErrRecipientNotFound    = errors.New("recipient not found")
+)
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link,omitempty"`
+	Data      Data       `json:"data,omitempty" gorm:"type:jsonb"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Data holds the ids a client needs to act on a notification, such as the
+// order it is about. It is stored as a JSON object.
+type Data map[string]string
+
+func (d Data) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (d *Data) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	case nil:
+		*d = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Data", src)
+	}
+}
+
+type NotificationFilter struct {
+	UserID uint
+	Type   string
+	Unread bool
+	Page   int
+	Limit  int
+}
+
+// Preference turns one channel on or off for one type of notification.
+// Every channel is on until the user says otherwise.
+type Preference struct {
+	UserID    uint      `json:"-" gorm:"primaryKey"`
+	EventType string    `json:"event_type" gorm:"primaryKey"`
+	Channel   string    `json:"channel" gorm:"primaryKey"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
+
+type PreferenceInput struct {
+	EventType string `json:"event_type" validate:"required"`
+	Channel   string `json:"channel" validate:"required"`
+	Enabled   *bool  `json:"enabled" validate:"required"`
+}
+
+type UpdatePreferencesInput struct {
+	Preferences []PreferenceInput `json:"preferences" validate:"required,min=1,max=100,dive"`
+}
+
+// Webhook is where the webhook channel posts a user's notifications. Each
+// request is signed with Secret, which is only shown when it is set.
+type Webhook struct {
+	UserID    uint      `json:"-" gorm:"primaryKey"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Webhook) TableName() string {
+	return "notification_webhooks"
+}
+
+// WebhookInput sets the webhook URL. Only https is accepted, so that
+// notifications and their signatures are not sent in the clear.
+type WebhookInput struct {
+	URL string `json:"url" validate:"required,http_url,startswith=https://,max=2048"`
+}
+
+// CreatedWebhook is returned once when a webhook is set and is the only
+// time its secret is shown.
+type CreatedWebhook struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// Recipient is who a notification is delivered to.
+type Recipient struct {
+	ID        uint
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+func (Recipient) TableName() string {
+	return "users"
+}
+
+// Channel delivers notifications outside the inbox, such as by email. A
+// module that adds a way to reach users registers a channel, and users
+// can then turn it on or off per type of notification by its name.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, recipient *Recipient, notification *Notification) error
+}
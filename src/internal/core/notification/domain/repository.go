@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	List(ctx context.Context, filter NotificationFilter) ([]*Notification, int64, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	// MarkRead sets read_at on the user's notification unless it is already
+	// read.
+	MarkRead(ctx context.Context, userID uint, id uuid.UUID, at time.Time) error
+	// MarkAllRead marks every unread notification of the user read and
+	// returns how many there were.
+	MarkAllRead(ctx context.Context, userID uint, at time.Time) (int64, error)
+	ListByUserID(ctx context.Context, userID uint) ([]*Notification, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+type PreferenceRepository interface {
+	ListByUserID(ctx context.Context, userID uint) ([]*Preference, error)
+	// Save creates or updates the given preferences in one transaction.
+	Save(ctx context.Context, preferences []*Preference) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+type WebhookRepository interface {
+	GetByUserID(ctx context.Context, userID uint) (*Webhook, error)
+	// Save creates the user's webhook or replaces its URL and secret.
+	Save(ctx context.Context, webhook *Webhook) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+type RecipientRepository interface {
+	// GetByID returns the user unless their account is anonymized.
+	GetByID(ctx context.Context, id uint) (*Recipient, error)
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+type NotificationService interface {
+	// Notify puts the notification in the user's inbox and hands it to every
+	// other channel, following the user's preferences for its type. Other
+	// channels deliver in the background, so their failures are only logged.
+	Notify(ctx context.Context, notification *Notification) error
+	// RegisterChannel adds a delivery channel. Modules call it while the app
+	// starts.
+	RegisterChannel(channel Channel)
+	// WaitForDeliveries blocks until the notifications handed to other
+	// channels are delivered or have failed.
+	WaitForDeliveries()
+
+	ListNotifications(ctx context.Context, filter NotificationFilter) ([]*Notification, int64, error)
+	UnreadCount(ctx context.Context, userID uint) (int64, error)
+	MarkRead(ctx context.Context, userID uint, id uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+
+	// GetPreferences returns the user's setting for every type of
+	// notification on every channel.
+	GetPreferences(ctx context.Context, userID uint) ([]*Preference, error)
+	UpdatePreferences(ctx context.Context, userID uint, input UpdatePreferencesInput) ([]*Preference, error)
+
+	GetWebhook(ctx context.Context, userID uint) (*Webhook, error)
+	// SetWebhook points the user's webhook at a URL with a new secret.
+	SetWebhook(ctx context.Context, userID uint, input WebhookInput) (*CreatedWebhook, error)
+	DeleteWebhook(ctx context.Context, userID uint) error
+
+	// ExportUserData returns a user's notifications and settings for their
+	// personal data export.
+	ExportUserData(ctx context.Context, userID uint) (interface{}, error)
+	// DeleteUserData deletes a user's notifications and settings.
+	DeleteUserData(ctx context.Context, userID uint) error
+}
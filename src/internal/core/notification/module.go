@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/notification/channel"
+	"golang_marketplace/src/internal/core/notification/delivery/http"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/internal/core/notification/repository"
+	"golang_marketplace/src/internal/core/notification/service"
+	"golang_marketplace/src/internal/platform/mailer"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Module struct {
+	Service domain.NotificationService
+	logger  *slog.Logger
+}
+
+// NewModule sets up the inbox with the email and webhook channels. Other
+// modules add channels through Service.RegisterChannel.
+func NewModule(db *gorm.DB, mailer mailer.Mailer, logger *slog.Logger) *Module {
+	logger = logger.With("module", "notification")
+
+	notificationRepo := repository.NewNotificationRepository(db)
+	preferenceRepo := repository.NewPreferenceRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	recipientRepo := repository.NewRecipientRepository(db)
+
+	notificationService := service.NewNotificationService(notificationRepo, preferenceRepo, webhookRepo, recipientRepo, logger)
+	notificationService.RegisterChannel(channel.NewEmail(mailer))
+	notificationService.RegisterChannel(channel.NewWebhook(webhookRepo))
+
+	return &Module{
+		Service: notificationService,
+		logger:  logger,
+	}
+}
+
+func (m *Module) RegisterRoutes(router *gin.RouterGroup, authenticate gin.HandlerFunc) {
+	http.RegisterRoutes(router, m.Service, authenticate, m.logger)
+}
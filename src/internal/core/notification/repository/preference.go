@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type preferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferenceRepository(db *gorm.DB) domain.PreferenceRepository {
+	return &preferenceRepository{db: db}
+}
+
+func (r *preferenceRepository) ListByUserID(ctx context.Context, userID uint) ([]*domain.Preference, error) {
+	var preferences []*domain.Preference
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Where("user_id = ?", userID).
+		Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *preferenceRepository) Save(ctx context.Context, preferences []*domain.Preference) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_type"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(preferences).Error
+}
+
+func (r *preferenceRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.Preference{}).Error
+}
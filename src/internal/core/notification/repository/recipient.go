@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"gorm.io/gorm"
+)
+
+type recipientRepository struct {
+	db *gorm.DB
+}
+
+func NewRecipientRepository(db *gorm.DB) domain.RecipientRepository {
+	return &recipientRepository{db: db}
+}
+
+func (r *recipientRepository) GetByID(ctx context.Context, id uint) (*domain.Recipient, error) {
+	var recipient domain.Recipient
+	err := r.db.WithContext(ctx).
+		Select("id", "email", "first_name", "last_name").
+		Where("anonymized_at IS NULL").
+		First(&recipient, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recipient, nil
+}
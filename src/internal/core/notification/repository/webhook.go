@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) GetByUserID(ctx context.Context, userID uint) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&webhook, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) Save(ctx context.Context, webhook *domain.Webhook) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"url", "secret", "updated_at"}),
+		}).
+		Create(webhook).Error
+}
+
+func (r *webhookRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
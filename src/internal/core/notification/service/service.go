@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// deliveryTimeout bounds how long the channels outside the inbox get to
+// deliver one notification.
+const deliveryTimeout = 30 * time.Second
+
+type notificationService struct {
+	notificationRepo domain.NotificationRepository
+	preferenceRepo   domain.PreferenceRepository
+	webhookRepo      domain.WebhookRepository
+	recipientRepo    domain.RecipientRepository
+	logger           *slog.Logger
+
+	channels []domain.Channel
+	// deliveries tracks the background deliveries so that shutdown can
+	// wait for them.
+	deliveries sync.WaitGroup
+}
+
+func NewNotificationService(
+	notificationRepo domain.NotificationRepository,
+	preferenceRepo domain.PreferenceRepository,
+	webhookRepo domain.WebhookRepository,
+	recipientRepo domain.RecipientRepository,
+	logger *slog.Logger,
+) domain.NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		webhookRepo:      webhookRepo,
+		recipientRepo:    recipientRepo,
+		logger:           logger,
+	}
+}
+
+func (s *notificationService) RegisterChannel(channel domain.Channel) {
+	s.channels = append(s.channels, channel)
+}
+
+func (s *notificationService) Notify(ctx context.Context, notification *domain.Notification) error {
+	if !slices.Contains(domain.EventTypes, notification.Type) {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownEventType, notification.Type)
+	}
+
+	enabled, err := s.enabledChannels(ctx, notification.UserID, notification.Type)
+	if err != nil {
+		return err
+	}
+
+	if notification.ID == uuid.Nil {
+		notification.ID = uuid.New()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	if enabled[domain.ChannelInApp] {
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			return fmt.Errorf("failed to create notification: %w", err)
+		}
+	}
+
+	var channels []domain.Channel
+	for _, channel := range s.channels {
+		if enabled[channel.Name()] {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) > 0 {
+		s.deliveries.Add(1)
+		go func(notification domain.Notification) {
+			defer s.deliveries.Done()
+			s.deliver(context.WithoutCancel(ctx), channels, notification)
+		}(*notification)
+	}
+	return nil
+}
+
+func (s *notificationService) WaitForDeliveries() {
+	s.deliveries.Wait()
+}
+
+// deliver hands a notification to the channels outside the inbox, one after
+// the other.
+func (s *notificationService) deliver(ctx context.Context, channels []domain.Channel, notification domain.Notification) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	recipient, err := s.recipientRepo.GetByID(ctx, notification.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get notification recipient", "notification_id", notification.ID, "user_id", notification.UserID, "error", err)
+		return
+	}
+
+	for _, channel := range channels {
+		if err := channel.Send(ctx, recipient, &notification); err != nil {
+			s.logger.WarnContext(ctx, "failed to deliver notification",
+				"channel", channel.Name(), "notification_id", notification.ID, "user_id", notification.UserID, "error", err)
+		}
+	}
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, filter domain.NotificationFilter) ([]*domain.Notification, int64, error) {
+	notifications, total, err := s.notificationRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return notifications, total, nil
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context, userID uint) (int64, error) {
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID uint, id uuid.UUID) error {
+	err := s.notificationRepo.MarkRead(ctx, userID, id, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrNotificationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	count, err := s.notificationRepo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return count, nil
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID uint) ([]*domain.Preference, error) {
+	saved, err := s.preferenceRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	channels := s.channelNames()
+	preferences := make([]*domain.Preference, 0, len(domain.EventTypes)*len(channels))
+	for _, eventType := range domain.EventTypes {
+		for _, channel := range channels {
+			preference := &domain.Preference{UserID: userID, EventType: eventType, Channel: channel, Enabled: true}
+			for _, p := range saved {
+				if p.EventType == eventType && p.Channel == channel {
+					preference = p
+					break
+				}
+			}
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID uint, input domain.UpdatePreferencesInput) ([]*domain.Preference, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	channels := s.channelNames()
+	now := time.Now()
+	preferences := make([]*domain.Preference, 0, len(input.Preferences))
+	// one upsert cannot touch a row twice, so a repeated pair keeps its
+	// last setting
+	seen := make(map[[2]string]int, len(input.Preferences))
+	for _, p := range input.Preferences {
+		if !slices.Contains(domain.EventTypes, p.EventType) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownEventType, p.EventType)
+		}
+		if !slices.Contains(channels, p.Channel) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownChannel, p.Channel)
+		}
+		if i, ok := seen[[2]string{p.EventType, p.Channel}]; ok {
+			preferences[i].Enabled = *p.Enabled
+			continue
+		}
+		seen[[2]string{p.EventType, p.Channel}] = len(preferences)
+		preferences = append(preferences, &domain.Preference{
+			UserID:    userID,
+			EventType: p.EventType,
+			Channel:   p.Channel,
+			Enabled:   *p.Enabled,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.preferenceRepo.Save(ctx, preferences); err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *notificationService) ExportUserData(ctx context.Context, userID uint) (interface{}, error) {
+	notifications, err := s.notificationRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.webhookRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return exportedData{
+		Notifications: notifications,
+		Preferences:   preferences,
+		Webhook:       webhook,
+	}, nil
+}
+
+func (s *notificationService) DeleteUserData(ctx context.Context, userID uint) error {
+	if err := s.notificationRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	if err := s.preferenceRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete notification preferences: %w", err)
+	}
+	if err := s.webhookRepo.DeleteByUserID(ctx, userID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// enabledChannels tells which channels the user wants notifications of the
+// given type on. Channels they never set are on.
+func (s *notificationService) enabledChannels(ctx context.Context, userID uint, eventType string) (map[string]bool, error) {
+	saved, err := s.preferenceRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	enabled := make(map[string]bool)
+	for _, channel := range s.channelNames() {
+		enabled[channel] = true
+	}
+	for _, p := range saved {
+		if p.EventType == eventType {
+			enabled[p.Channel] = p.Enabled
+		}
+	}
+	return enabled, nil
+}
+
+// channelNames lists the inbox followed by the registered channels.
+func (s *notificationService) channelNames() []string {
+	names := []string{domain.ChannelInApp}
+	for _, channel := range s.channels {
+		names = append(names, channel.Name())
+	}
+	return names
+}
+
+type exportedData struct {
+	Notifications []*domain.Notification `json:"notifications"`
+	Preferences   []*domain.Preference   `json:"preferences"`
+	Webhook       *domain.Webhook        `json:"webhook,omitempty"`
+}
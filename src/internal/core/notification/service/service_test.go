@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubNotifications struct {
+	domain.NotificationRepository
+	mu      sync.Mutex
+	created []*domain.Notification
+}
+
+func (r *stubNotifications) Create(_ context.Context, notification *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.created = append(r.created, notification)
+	return nil
+}
+
+type stubPreferences struct {
+	domain.PreferenceRepository
+	saved []*domain.Preference
+}
+
+func (r *stubPreferences) Save(_ context.Context, preferences []*domain.Preference) error {
+	r.saved = preferences
+	return nil
+}
+
+func (r *stubPreferences) ListByUserID(context.Context, uint) ([]*domain.Preference, error) {
+	return r.saved, nil
+}
+
+type stubRecipients struct {
+	domain.RecipientRepository
+}
+
+func (stubRecipients) GetByID(_ context.Context, id uint) (*domain.Recipient, error) {
+	return &domain.Recipient{ID: id}, nil
+}
+
+// slowChannel takes a while to deliver, so that a test can tell whether
+// WaitForDeliveries waited for it.
+type slowChannel struct {
+	name string
+	mu   sync.Mutex
+	sent []string
+}
+
+func (c *slowChannel) Name() string { return c.name }
+
+func (c *slowChannel) Send(_ context.Context, _ *domain.Recipient, notification *domain.Notification) error {
+	time.Sleep(20 * time.Millisecond)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, notification.Type)
+	return nil
+}
+
+func TestNotifyFollowsPreferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		saved       []*domain.Preference
+		wantInbox   bool
+		wantEmailed bool
+	}{
+		{name: "everything on by default", wantInbox: true, wantEmailed: true},
+		{
+			name:      "email turned off",
+			saved:     []*domain.Preference{{EventType: domain.EventPriceDropped, Channel: domain.ChannelEmail}},
+			wantInbox: true,
+		},
+		{
+			name:        "inbox turned off",
+			saved:       []*domain.Preference{{EventType: domain.EventPriceDropped, Channel: domain.ChannelInApp}},
+			wantEmailed: true,
+		},
+		{
+			name:        "another type turned off",
+			saved:       []*domain.Preference{{EventType: domain.EventPayoutSent, Channel: domain.ChannelEmail}},
+			wantInbox:   true,
+			wantEmailed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbox := &stubNotifications{}
+			email := &slowChannel{name: domain.ChannelEmail}
+			s := NewNotificationService(inbox, &stubPreferences{saved: tt.saved}, nil, stubRecipients{},
+				slog.New(slog.NewTextHandler(io.Discard, nil)))
+			s.RegisterChannel(email)
+
+			err := s.Notify(context.Background(), &domain.Notification{UserID: 1, Type: domain.EventPriceDropped, Title: "Cheaper"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.WaitForDeliveries()
+
+			if got := len(inbox.created) == 1; got != tt.wantInbox {
+				t.Errorf("in inbox = %v, want %v", got, tt.wantInbox)
+			}
+			if got := len(email.sent) == 1; got != tt.wantEmailed {
+				t.Errorf("emailed = %v, want %v", got, tt.wantEmailed)
+			}
+		})
+	}
+}
+
+func TestNotifyRejectsUnknownTypes(t *testing.T) {
+	s := NewNotificationService(&stubNotifications{}, &stubPreferences{}, nil, stubRecipients{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	err := s.Notify(context.Background(), &domain.Notification{UserID: 1, Type: "made.up"})
+	if !errors.Is(err, domain.ErrUnknownEventType) {
+		t.Errorf("err = %v, want %v", err, domain.ErrUnknownEventType)
+	}
+}
+
+func TestWaitForDeliveriesOutlivesTheRequest(t *testing.T) {
+	email := &slowChannel{name: domain.ChannelEmail}
+	s := NewNotificationService(&stubNotifications{}, &stubPreferences{}, nil, stubRecipients{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.RegisterChannel(email)
+
+	// the request that caused the notifications ends straight away
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		if err := s.Notify(ctx, &domain.Notification{UserID: 1, Type: domain.EventBackInStock}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	s.WaitForDeliveries()
+
+	email.mu.Lock()
+	defer email.mu.Unlock()
+	if len(email.sent) != 3 {
+		t.Errorf("%d notifications delivered before WaitForDeliveries returned, want 3", len(email.sent))
+	}
+}
+
+func TestUpdatePreferencesKeepsTheLastOfRepeatedPairs(t *testing.T) {
+	preferences := &stubPreferences{}
+	s := NewNotificationService(&stubNotifications{}, preferences, nil, stubRecipients{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.RegisterChannel(&slowChannel{name: domain.ChannelEmail})
+
+	off, on := false, true
+	_, err := s.UpdatePreferences(context.Background(), 1, domain.UpdatePreferencesInput{Preferences: []domain.PreferenceInput{
+		{EventType: domain.EventPriceDropped, Channel: domain.ChannelInApp, Enabled: &on},
+		{EventType: domain.EventPriceDropped, Channel: domain.ChannelEmail, Enabled: &on},
+		{EventType: domain.EventPriceDropped, Channel: domain.ChannelInApp, Enabled: &off},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(preferences.saved) != 2 {
+		t.Fatalf("saved %d preferences, want 2", len(preferences.saved))
+	}
+	if p := preferences.saved[0]; p.Channel != domain.ChannelInApp || p.Enabled {
+		t.Errorf("in-app preference = %+v, want the last one, disabled", p)
+	}
+}
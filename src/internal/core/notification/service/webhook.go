@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+func (s *notificationService) GetWebhook(ctx context.Context, userID uint) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *notificationService) SetWebhook(ctx context.Context, userID uint, input domain.WebhookInput) (*domain.CreatedWebhook, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	now := time.Now()
+	webhook := &domain.Webhook{
+		UserID:    userID,
+		URL:       strings.TrimSpace(input.URL),
+		Secret:    hex.EncodeToString(raw),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.webhookRepo.Save(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	return &domain.CreatedWebhook{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+func (s *notificationService) DeleteWebhook(ctx context.Context, userID uint) error {
+	err := s.webhookRepo.DeleteByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/product/delivery/dto"
+	"golang_marketplace/src/internal/core/product/domain"
+	"net/http"
+)
+
+// authorizeSeller reports whether the caller may act for sellerID. Admins
+// may act for every seller; sellers only for the seller linked to their
+// account. It writes the error response when the answer is no.
+func (h *ProductHandler) authorizeSeller(c *gin.Context, sellerID uuid.UUID) bool {
+	ctx := c.Request.Context()
+
+	claims, ok := authdomain.ClaimsFromContext(ctx)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{Error: "authentication required"})
+		return false
+	}
+	if claims.HasRole(authdomain.RoleAdmin) {
+		return true
+	}
+
+	seller, err := h.service.GetSellerByUserID(ctx, claims.UserID)
+	if errors.Is(err, domain.ErrSellerNotFound) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "no seller account is linked to this user"})
+		return false
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get seller for user", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+		return false
+	}
+
+	if seller.ID != sellerID {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: "variant belongs to another seller"})
+		return false
+	}
+	return true
+}
+
+// authorizeVariant loads a variant and checks the caller may change it.
+func (h *ProductHandler) authorizeVariant(c *gin.Context, id uuid.UUID) bool {
+	variant, err := h.service.GetProductVariant(c.Request.Context(), id)
+	if errors.Is(err, domain.ErrVariantNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return false
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "failed to get product variant", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+		return false
+	}
+
+	return h.authorizeSeller(c, variant.SellerID)
+}
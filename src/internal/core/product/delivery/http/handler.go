@@ -1,22 +1,26 @@
 package http
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang_marketplace/src/internal/core/product/delivery/dto"
 	"golang_marketplace/src/internal/core/product/domain"
-	"log"
+	"golang_marketplace/src/pkg/validator"
+	"log/slog"
 	"net/http"
 	"strconv"
 )
 
 type ProductHandler struct {
 	service domain.ProductService
+	logger  *slog.Logger
 }
 
-func NewProductHandler(service domain.ProductService) *ProductHandler {
+func NewProductHandler(service domain.ProductService, logger *slog.Logger) *ProductHandler {
 	return &ProductHandler{
 		service: service,
+		logger:  logger,
 	}
 }
 
@@ -30,18 +34,21 @@ func NewProductHandler(service domain.ProductService) *ProductHandler {
 // @Success 201 {object} domain.Product
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products [post]
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req domain.CreateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	product, err := h.service.CreateProduct(c.Request.Context(), req)
 	if err != nil {
-		log.Println(err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to create product", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -63,13 +70,13 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid product ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid product ID"})
 		return
 	}
 
 	product, err := h.service.GetProduct(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -87,24 +94,27 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 // @Success 200 {object} domain.Product
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid product ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid product ID"})
 		return
 	}
 
 	var req domain.UpdateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	product, err := h.service.UpdateProduct(c.Request.Context(), id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -121,18 +131,21 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 // @Success 204
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid product ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid product ID"})
 		return
 	}
 
 	if err := h.service.DeleteProduct(c.Request.Context(), id); err != nil {
-		log.Println("Failed to delete product: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to delete product", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -162,12 +175,12 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 
 	products, total, err := h.service.ListProducts(c.Request.Context(), filter)
 	if err != nil {
-		log.Println("Failed to list products: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to list products", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	response := ProductListResponse{
+	response := dto.ProductListResponse{
 		Products: products,
 		Total:    total,
 		Page:     filter.Page,
@@ -194,7 +207,7 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 func (h *ProductHandler) SearchProducts(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Search query is required"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Search query is required"})
 		return
 	}
 
@@ -202,12 +215,12 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 
 	products, total, err := h.service.SearchProducts(c.Request.Context(), query, filter)
 	if err != nil {
-		log.Println("Failed to search products: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to search products", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	response := ProductListResponse{
+	response := dto.ProductListResponse{
 		Products: products,
 		Total:    total,
 		Page:     filter.Page,
@@ -226,18 +239,25 @@ func (h *ProductHandler) SearchProducts(c *gin.Context) {
 // @Param variant body domain.CreateVariantRequest true "Variant data"
 // @Success 201 {object} domain.ProductVariant
 // @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products/variants [post]
 func (h *ProductHandler) AddProductVariant(c *gin.Context) {
 	var req domain.CreateVariantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if !h.authorizeSeller(c, req.SellerID) {
 		return
 	}
 
 	variant, err := h.service.AddProductVariant(c.Request.Context(), req)
 	if err != nil {
-		log.Println("Failed to add product variant: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to add product variant", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -254,25 +274,32 @@ func (h *ProductHandler) AddProductVariant(c *gin.Context) {
 // @Param variant body domain.UpdateVariantRequest true "Variant update data"
 // @Success 200 {object} domain.ProductVariant
 // @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products/variants/{id} [put]
 func (h *ProductHandler) UpdateProductVariant(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid variant ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid variant ID"})
+		return
+	}
+
+	if !h.authorizeVariant(c, id) {
 		return
 	}
 
 	var req domain.UpdateVariantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	variant, err := h.service.UpdateProductVariant(c.Request.Context(), id, req)
 	if err != nil {
-		log.Println("Failed to update product variant: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to update product variant", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -288,52 +315,170 @@ func (h *ProductHandler) UpdateProductVariant(c *gin.Context) {
 // @Param id path string true "Variant ID"
 // @Success 204
 // @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /products/variants/{id} [delete]
 func (h *ProductHandler) DeleteProductVariant(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid variant ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid variant ID"})
+		return
+	}
+
+	if !h.authorizeVariant(c, id) {
 		return
 	}
 
 	if err := h.service.DeleteProductVariant(c.Request.Context(), id); err != nil {
-		log.Println("Failed to delete product variant: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to delete product variant", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// AdjustStock godoc
+// @Summary Adjust variant stock
+// @Description Add to or remove from the stock of a product variant
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Variant ID"
+// @Param stock body domain.AdjustStockRequest true "Stock change"
+// @Success 200 {object} domain.ProductVariant
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /products/variants/{id}/stock [post]
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid variant ID"})
+		return
+	}
+
+	if !h.authorizeVariant(c, id) {
+		return
+	}
+
+	var req domain.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validator.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	err = h.service.UpdateStock(c.Request.Context(), id, req.Quantity)
+	if errors.Is(err, domain.ErrVariantNotFound) {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrInsufficientStock) {
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "failed to adjust stock", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	variant, err := h.service.GetProductVariant(c.Request.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "failed to get product variant", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, variant)
+}
+
 // GetVariantsByProduct godoc
 // @Summary Get variants by product ID
 // @Description Get all variants for a specific product
 // @Tags variants
 // @Accept json
 // @Produce json
-// @Param product_id path string true "Product ID"
+// @Param id path string true "Product ID"
 // @Success 200 {array} domain.ProductVariant
 // @Failure 400 {object} ErrorResponse
-// @Router /products/{product_id}/variants [get]
+// @Router /products/{id}/variants [get]
 func (h *ProductHandler) GetVariantsByProduct(c *gin.Context) {
-	productIDStr := c.Param("product_id")
+	productIDStr := c.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid product ID"})
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid product ID"})
 		return
 	}
 
 	variants, err := h.service.GetVariantsByProduct(c.Request.Context(), productID)
 	if err != nil {
-		log.Println("Failed to get variants by product: ", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "failed to get variants by product", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, variants)
 }
 
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Create a new category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body domain.CreateCategoryRequest true "Category data"
+// @Success 201 {object} domain.Category
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /categories [post]
+func (h *ProductHandler) CreateCategory(c *gin.Context) {
+	var req domain.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), req)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "failed to create category", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// ListCategories godoc
+// @Summary List categories
+// @Description List active categories
+// @Tags categories
+// @Produce json
+// @Success 200 {array} domain.Category
+// @Router /categories [get]
+func (h *ProductHandler) ListCategories(c *gin.Context) {
+	categories, err := h.service.ListCategories(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "failed to list categories", "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
 func (h *ProductHandler) parseProductFilter(c *gin.Context) domain.ProductFilter {
 	filter := domain.ProductFilter{
 		Page:  1,
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/core/product/repository"
+	"golang_marketplace/src/internal/core/product/service"
+	"golang_marketplace/src/internal/platform/cache"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// catalogDriver is a database/sql driver serving one seller's variant, so
+// handler tests run the real repositories and catch queries GORM rejects.
+type catalogDriver struct {
+	mu       sync.Mutex
+	variant  uuid.UUID
+	product  uuid.UUID
+	seller   uuid.UUID
+	sellerOf uint
+	stock    int64
+}
+
+var (
+	driverOnce sync.Once
+	testDriver = &catalogDriver{}
+)
+
+func (d *catalogDriver) Open(string) (driver.Conn, error) {
+	return &catalogConn{d: d}, nil
+}
+
+type catalogConn struct {
+	d *catalogDriver
+}
+
+func (c *catalogConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *catalogConn) Close() error { return nil }
+
+// Begin returns a transaction that does nothing, for GORM's implicit
+// transactions around writes.
+func (c *catalogConn) Begin() (driver.Tx, error) {
+	return catalogTx{}, nil
+}
+
+type catalogTx struct{}
+
+func (catalogTx) Commit() error { return nil }
+
+func (catalogTx) Rollback() error { return nil }
+
+func (c *catalogConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	d := c.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, `UPDATE "product_variants"`) && strings.Contains(query, "stock + "):
+		rows := &catalogRows{columns: []string{"id", "product_id", "seller_id", "price", "stock", "is_active"}}
+		quantity := args[0].Value.(int64)
+		if args[2].Value == d.variant.String() && d.stock+quantity >= 0 {
+			d.stock += quantity
+			rows.values = [][]driver.Value{{d.variant.String(), d.product.String(), d.seller.String(), 10.5, d.stock, true}}
+		}
+		return rows, nil
+	case strings.Contains(query, `FROM "product_variants"`) && strings.Contains(query, "count("):
+		if args[0].Value != d.variant.String() {
+			return &catalogRows{columns: []string{"count"}, values: [][]driver.Value{{int64(0)}}}, nil
+		}
+		return &catalogRows{columns: []string{"count"}, values: [][]driver.Value{{int64(1)}}}, nil
+	case strings.Contains(query, `FROM "product_variants"`):
+		rows := &catalogRows{columns: []string{"id", "product_id", "seller_id", "price", "stock", "is_active"}}
+		if args[0].Value == d.variant.String() {
+			rows.values = [][]driver.Value{{d.variant.String(), d.product.String(), d.seller.String(), 10.5, d.stock, true}}
+		}
+		return rows, nil
+	case strings.Contains(query, `FROM "products"`):
+		rows := &catalogRows{columns: []string{"id", "category_id", "status"}}
+		if args[0].Value == d.product.String() {
+			rows.values = [][]driver.Value{{d.product.String(), uuid.NewString(), "active"}}
+		}
+		return rows, nil
+	case strings.Contains(query, `FROM "sellers"`):
+		rows := &catalogRows{columns: []string{"id", "user_id", "company_name"}}
+		if args[0].Value == int64(d.sellerOf) {
+			rows.values = [][]driver.Value{{d.seller.String(), int64(d.sellerOf), "Acme"}}
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type catalogRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *catalogRows) Columns() []string { return r.columns }
+
+func (r *catalogRows) Close() error { return nil }
+
+func (r *catalogRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTestRouter(t *testing.T, claims *authdomain.Claims, stock int64) (*gin.Engine, *catalogDriver) {
+	t.Helper()
+	driverOnce.Do(func() { sql.Register("catalogtest", testDriver) })
+
+	testDriver.mu.Lock()
+	testDriver.variant = uuid.New()
+	testDriver.product = uuid.New()
+	testDriver.seller = uuid.New()
+	testDriver.sellerOf = 7
+	testDriver.stock = stock
+	testDriver.mu.Unlock()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "catalogtest"}), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	opts := cache.LoaderOptions{SoftTTL: time.Minute, HardTTL: time.Hour, NegativeTTL: time.Minute}
+	svc := service.NewProductService(
+		repository.NewProductRepository(db),
+		repository.NewProductVariantRepository(db),
+		repository.NewCategoryRepository(db),
+		repository.NewSellerRepository(db),
+		cache.NewMemoryCache(100),
+		service.CacheOptions{Product: opts, List: opts},
+		logger,
+	)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(authdomain.ContextWithClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+	RegisterRoutes(router.Group(""), svc, authenticate, nil, logger)
+	return router, testDriver
+}
+
+func TestAdjustStock(t *testing.T) {
+	owner := &authdomain.Claims{UserID: 7, Role: authdomain.RoleSeller, EmailVerified: true}
+	stranger := &authdomain.Claims{UserID: 8, Role: authdomain.RoleSeller, EmailVerified: true}
+
+	tests := []struct {
+		name      string
+		claims    *authdomain.Claims
+		quantity  int
+		wantCode  int
+		wantStock int64
+	}{
+		{name: "owner adds stock", claims: owner, quantity: 5, wantCode: http.StatusOK, wantStock: 8},
+		{name: "owner removes stock", claims: owner, quantity: -3, wantCode: http.StatusOK, wantStock: 0},
+		{name: "owner removes more than there is", claims: owner, quantity: -4, wantCode: http.StatusConflict, wantStock: 3},
+		{name: "other seller", claims: stranger, quantity: 5, wantCode: http.StatusForbidden, wantStock: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, db := newTestRouter(t, tt.claims, 3)
+
+			body := strings.NewReader(`{"quantity":` + strconv.Itoa(tt.quantity) + `}`)
+			req := httptest.NewRequest(http.MethodPost, "/products/variants/"+db.variant.String()+"/stock", body)
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if db.stock != tt.wantStock {
+				t.Errorf("stock = %d, want %d", db.stock, tt.wantStock)
+			}
+			if tt.wantCode == http.StatusOK {
+				var variant domain.ProductVariant
+				if err := json.Unmarshal(rec.Body.Bytes(), &variant); err != nil {
+					t.Fatal(err)
+				}
+				if variant.ID != db.variant || int64(variant.Stock) != tt.wantStock {
+					t.Errorf("got variant %s with stock %d", variant.ID, variant.Stock)
+				}
+			}
+		})
+	}
+}
+
+func TestAdjustStockUnknownVariant(t *testing.T) {
+	admin := &authdomain.Claims{UserID: 1, Role: authdomain.RoleAdmin, EmailVerified: true}
+	router, _ := newTestRouter(t, admin, 3)
+
+	req := httptest.NewRequest(http.MethodPost, "/products/variants/"+uuid.NewString()+"/stock", strings.NewReader(`{"quantity":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body.String())
+	}
+}
@@ -2,27 +2,43 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+	authhttp "golang_marketplace/src/internal/core/auth/delivery/http"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
 	"golang_marketplace/src/internal/core/product/domain"
+	"log/slog"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, service domain.ProductService) {
-	handler := NewProductHandler(service)
+func RegisterRoutes(router *gin.RouterGroup, service domain.ProductService, authenticate gin.HandlerFunc, trackViews []gin.HandlerFunc, logger *slog.Logger) {
+	handler := NewProductHandler(service, logger)
+
+	adminOnly := authhttp.RequireRole(authdomain.RoleAdmin)
+	sellerOrAdmin := authhttp.RequireRole(authdomain.RoleSeller, authdomain.RoleAdmin)
+	verified := authhttp.RequireVerifiedEmail()
 
 	products := router.Group("/products")
 	{
-		products.POST("", handler.CreateProduct)
+		products.POST("", authenticate, sellerOrAdmin, verified, authhttp.RequireScope(authdomain.ScopeProductsWrite), handler.CreateProduct)
 		products.GET("", handler.ListProducts)
 		products.GET("/search", handler.SearchProducts)
-		products.GET("/:id", handler.GetProduct)
-		products.PUT("/:id", handler.UpdateProduct)
-		products.DELETE("/:id", handler.DeleteProduct)
-		products.GET("/:product_id/variants", handler.GetVariantsByProduct)
+		products.GET("/:id", append(trackViews, handler.GetProduct)...)
+		products.PUT("/:id", authenticate, adminOnly, authhttp.RequireSession(), handler.UpdateProduct)
+		products.DELETE("/:id", authenticate, adminOnly, authhttp.RequireSession(), handler.DeleteProduct)
+		products.GET("/:id/variants", handler.GetVariantsByProduct)
+	}
+
+	// sellers may only touch their own variants; the handlers check ownership
+	variantsWrite := authhttp.RequireScope(authdomain.ScopeVariantsWrite)
+	variants := router.Group("/products/variants", authenticate, sellerOrAdmin, verified)
+	{
+		variants.POST("", variantsWrite, handler.AddProductVariant)
+		variants.PUT("/:id", variantsWrite, handler.UpdateProductVariant)
+		variants.DELETE("/:id", variantsWrite, handler.DeleteProductVariant)
+		variants.POST("/:id/stock", authhttp.RequireScope(authdomain.ScopeStockWrite), handler.AdjustStock)
 	}
 
-	variants := router.Group("/products/variants")
+	categories := router.Group("/categories")
 	{
-		variants.POST("", handler.AddProductVariant)
-		variants.PUT("/:id", handler.UpdateProductVariant)
-		variants.DELETE("/:id", handler.DeleteProductVariant)
+		categories.GET("", handler.ListCategories)
+		categories.POST("", authenticate, adminOnly, authhttp.RequireSession(), handler.CreateCategory)
 	}
 }
@@ -1,10 +1,17 @@
 package domain
 
 import (
+	"errors"
 	"github.com/google/uuid"
 	"time"
 )
 
+var (
+	ErrVariantNotFound   = errors.New("variant not found")
+	ErrSellerNotFound    = errors.New("seller not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
 type Product struct {
 	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Name        string    `json:"name"`
@@ -42,6 +49,7 @@ type Category struct {
 
 type Seller struct {
 	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      *uint     `json:"user_id,omitempty" gorm:"uniqueIndex"`
 	CompanyName string    `json:"company_name" gorm:"not null"`
 	Email       string    `json:"email" gorm:"unique;not null"`
 	Phone       string    `json:"phone"`
@@ -8,6 +8,9 @@ import (
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Product, error)
+	// GetByIDFromPrimary is GetByID without the replicas, for reads that a
+	// write is based on or that refill the cache after one.
+	GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*Product, error)
 	GetBySKU(ctx context.Context, sku string) (*Product, error)
 	Update(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -18,11 +21,16 @@ type ProductRepository interface {
 type ProductVariantRepository interface {
 	Create(ctx context.Context, variant *ProductVariant) error
 	GetByID(ctx context.Context, id uuid.UUID) (*ProductVariant, error)
+	GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*ProductVariant, error)
 	GetByProductID(ctx context.Context, productID uuid.UUID) ([]*ProductVariant, error)
+	GetByProductIDFromPrimary(ctx context.Context, productID uuid.UUID) ([]*ProductVariant, error)
 	GetBySellerID(ctx context.Context, sellerID uuid.UUID, filter ProductFilter) ([]*ProductVariant, int64, error)
 	Update(ctx context.Context, variant *ProductVariant) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	UpdateStock(ctx context.Context, id uuid.UUID, quantity int) error
+	// UpdateStock adds quantity to the variant's stock, which may be
+	// negative, and returns the updated variant. Stock never drops below
+	// zero; ErrInsufficientStock is returned instead.
+	UpdateStock(ctx context.Context, id uuid.UUID, quantity int) (*ProductVariant, error)
 }
 
 type CategoryRepository interface {
@@ -33,6 +41,13 @@ type CategoryRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+type SellerRepository interface {
+	GetByUserID(ctx context.Context, userID uint) (*Seller, error)
+	// AnonymizeByUserID replaces the contact details of the user's seller
+	// with tombstones. The seller stays, as its variants refer to it.
+	AnonymizeByUserID(ctx context.Context, userID uint) error
+}
+
 type ProductFilter struct {
 	CategoryID *uuid.UUID
 	SellerID   *uuid.UUID
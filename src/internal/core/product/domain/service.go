@@ -14,6 +14,7 @@ type ProductService interface {
 	SearchProducts(ctx context.Context, query string, filter ProductFilter) ([]*ProductWithVariants, int64, error)
 
 	AddProductVariant(ctx context.Context, req CreateVariantRequest) (*ProductVariant, error)
+	GetProductVariant(ctx context.Context, id uuid.UUID) (*ProductVariant, error)
 	UpdateProductVariant(ctx context.Context, id uuid.UUID, req UpdateVariantRequest) (*ProductVariant, error)
 	DeleteProductVariant(ctx context.Context, id uuid.UUID) error
 	GetVariantsByProduct(ctx context.Context, productID uuid.UUID) ([]*ProductVariant, error)
@@ -21,6 +22,14 @@ type ProductService interface {
 
 	UpdateStock(ctx context.Context, variantID uuid.UUID, quantity int) error
 	CheckStock(ctx context.Context, variantID uuid.UUID, quantity int) (bool, error)
+
+	CreateCategory(ctx context.Context, req CreateCategoryRequest) (*Category, error)
+	ListCategories(ctx context.Context) ([]*Category, error)
+
+	GetSellerByUserID(ctx context.Context, userID uint) (*Seller, error)
+	// AnonymizeSeller scrubs the contact details of the seller linked to a
+	// user whose account is being deleted.
+	AnonymizeSeller(ctx context.Context, userID uint) error
 }
 
 type CreateProductRequest struct {
@@ -58,6 +67,16 @@ type UpdateVariantRequest struct {
 	IsActive      *bool                  `json:"is_active,omitempty"`
 }
 
+// AdjustStockRequest changes stock by Quantity; negative values remove stock.
+type AdjustStockRequest struct {
+	Quantity int `json:"quantity" validate:"required"`
+}
+
+type CreateCategoryRequest struct {
+	Name     string     `json:"name" validate:"required,min=2,max=255"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
 type ProductWithVariants struct {
 	*Product
 	Variants []*ProductVariant `json:"variants"`
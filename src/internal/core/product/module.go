@@ -8,24 +8,33 @@ import (
 	"golang_marketplace/src/internal/core/product/service"
 	"golang_marketplace/src/internal/platform/cache"
 	"gorm.io/gorm"
+	"log/slog"
 )
 
 type Module struct {
 	Service domain.ProductService
+	logger  *slog.Logger
 }
 
-func NewModule(db *gorm.DB, cache *cache.Cache) *Module {
+func NewModule(db *gorm.DB, cache cache.Cache, cacheOpts service.CacheOptions, logger *slog.Logger) *Module {
+	logger = logger.With("module", "product")
+
 	productRepo := repository.NewProductRepository(db)
 	variantRepo := repository.NewProductVariantRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
+	sellerRepo := repository.NewSellerRepository(db)
 
-	productService := service.NewProductService(productRepo, variantRepo, categoryRepo, cache)
+	productService := service.NewProductService(productRepo, variantRepo, categoryRepo, sellerRepo, cache, cacheOpts, logger)
 
 	return &Module{
 		Service: productService,
+		logger:  logger,
 	}
 }
 
-func (m *Module) RegisterRoutes(router *gin.RouterGroup) {
-	http.RegisterRoutes(router, m.Service)
+// RegisterRoutes mounts the product routes. authenticate is put in front of
+// every route that changes the catalog, and trackViews in front of the
+// product page to record who looked at it.
+func (m *Module) RegisterRoutes(router *gin.RouterGroup, authenticate gin.HandlerFunc, trackViews ...gin.HandlerFunc) {
+	http.RegisterRoutes(router, m.Service, authenticate, trackViews, m.logger)
 }
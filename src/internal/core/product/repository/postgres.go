@@ -5,6 +5,8 @@ import (
 	"github.com/google/uuid"
 	"golang_marketplace/src/internal/core/product/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
 type productRepository struct {
@@ -20,11 +22,16 @@ func (r *productRepository) Create(ctx context.Context, product *domain.Product)
 }
 
 func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.getByID(r.db.WithContext(ctx), id)
+}
+
+func (r *productRepository) GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.getByID(r.db.WithContext(ctx).Clauses(dbresolver.Write), id)
+}
+
+func (r *productRepository) getByID(db *gorm.DB, id uuid.UUID) (*domain.Product, error) {
 	var product domain.Product
-	err := r.db.WithContext(ctx).
-		Preload("Category").
-		First(&product, "id = ?", id).Error
-	if err != nil {
+	if err := db.First(&product, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
@@ -32,8 +39,9 @@ func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 
 func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
 	var product domain.Product
+	// used for the uniqueness check before insert, so it must not see a lagging replica
 	err := r.db.WithContext(ctx).
-		Preload("Category").
+		Clauses(dbresolver.Write).
 		First(&product, "sku = ?", sku).Error
 	if err != nil {
 		return nil, err
@@ -53,7 +61,7 @@ func (r *productRepository) List(ctx context.Context, filter domain.ProductFilte
 	var products []*domain.Product
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&domain.Product{}).Preload("Category")
+	query := r.db.WithContext(ctx).Model(&domain.Product{})
 
 	query = r.applyFilters(query, filter)
 
@@ -88,7 +96,6 @@ func (r *productRepository) Search(ctx context.Context, query string, filter dom
 	var total int64
 
 	dbQuery := r.db.WithContext(ctx).Model(&domain.Product{}).
-		Preload("Category").
 		Where("name ILIKE ? OR description ILIKE ?", "%"+query+"%", "%"+query+"%")
 
 	dbQuery = r.applyFilters(dbQuery, filter)
@@ -134,21 +141,32 @@ func (r *productVariantRepository) Create(ctx context.Context, variant *domain.P
 }
 
 func (r *productVariantRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	return r.getByID(r.db.WithContext(ctx), id)
+}
+
+func (r *productVariantRepository) GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	return r.getByID(r.db.WithContext(ctx).Clauses(dbresolver.Write), id)
+}
+
+func (r *productVariantRepository) getByID(db *gorm.DB, id uuid.UUID) (*domain.ProductVariant, error) {
 	var variant domain.ProductVariant
-	err := r.db.WithContext(ctx).
-		Preload("Product").
-		Preload("Seller").
-		First(&variant, "id = ?", id).Error
-	if err != nil {
+	if err := db.First(&variant, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &variant, nil
 }
 
 func (r *productVariantRepository) GetByProductID(ctx context.Context, productID uuid.UUID) ([]*domain.ProductVariant, error) {
+	return r.getByProductID(r.db.WithContext(ctx), productID)
+}
+
+func (r *productVariantRepository) GetByProductIDFromPrimary(ctx context.Context, productID uuid.UUID) ([]*domain.ProductVariant, error) {
+	return r.getByProductID(r.db.WithContext(ctx).Clauses(dbresolver.Write), productID)
+}
+
+func (r *productVariantRepository) getByProductID(db *gorm.DB, productID uuid.UUID) ([]*domain.ProductVariant, error) {
 	var variants []*domain.ProductVariant
-	err := r.db.WithContext(ctx).
-		Preload("Product").
+	err := db.
 		Where("product_id = ? AND is_active = ?", productID, true).
 		Find(&variants).Error
 	return variants, err
@@ -159,7 +177,6 @@ func (r *productVariantRepository) GetBySellerID(ctx context.Context, sellerID u
 	var total int64
 
 	query := r.db.WithContext(ctx).Model(&domain.ProductVariant{}).
-		Preload("Product").
 		Where("seller_id = ?", sellerID)
 
 	if filter.MinPrice != nil {
@@ -169,7 +186,7 @@ func (r *productVariantRepository) GetBySellerID(ctx context.Context, sellerID u
 		query = query.Where("price <= ?", *filter.MaxPrice)
 	}
 	if filter.InStock != nil && *filter.InStock {
-		query = query.Where("quantity > 0")
+		query = query.Where("stock > 0")
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -196,8 +213,30 @@ func (r *productVariantRepository) Delete(ctx context.Context, id uuid.UUID) err
 	return r.db.WithContext(ctx).Delete(&domain.ProductVariant{}, id).Error
 }
 
-func (r *productVariantRepository) UpdateStock(ctx context.Context, id uuid.UUID, quantity int) error {
-	return r.db.WithContext(ctx).Model(&domain.ProductVariant{}).
+func (r *productVariantRepository) UpdateStock(ctx context.Context, id uuid.UUID, quantity int) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	result := r.db.WithContext(ctx).Model(&variant).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND stock + ? >= 0", id, quantity).
+		Update("stock", gorm.Expr("stock + ?", quantity))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &variant, nil
+	}
+
+	// nothing matched: either the variant is gone or there is not enough stock
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.ProductVariant{}).
+		Clauses(dbresolver.Write).
 		Where("id = ?", id).
-		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+		Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return nil, domain.ErrInsufficientStock
 }
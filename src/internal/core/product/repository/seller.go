@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/product/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+type sellerRepository struct {
+	db *gorm.DB
+}
+
+func NewSellerRepository(db *gorm.DB) domain.SellerRepository {
+	return &sellerRepository{db: db}
+}
+
+func (r *sellerRepository) GetByUserID(ctx context.Context, userID uint) (*domain.Seller, error) {
+	var seller domain.Seller
+	err := r.db.WithContext(ctx).First(&seller, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &seller, nil
+}
+
+func (r *sellerRepository) AnonymizeByUserID(ctx context.Context, userID uint) error {
+	// email is unique and required, so each seller gets its own tombstone
+	return r.db.WithContext(ctx).Model(&domain.Seller{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"email":      gorm.Expr("'deleted-seller-' || id::text || '@deleted.invalid'"),
+			"phone":      nil,
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/product/domain"
+	"strings"
+)
+
+// allProductsTag is carried by every list page that is not scoped to a
+// category, so that creating or removing a product refreshes them.
+const allProductsTag = "products"
+
+type productPage struct {
+	Products []*domain.ProductWithVariants `json:"products"`
+	Total    int64                         `json:"total"`
+}
+
+// productCacheKey is versioned with the loader's envelope; entries cached
+// before it hold a bare product, which the envelope cannot decode.
+func productCacheKey(id uuid.UUID) string {
+	return fmt.Sprintf("product:v2:%s", id.String())
+}
+
+func productTag(id uuid.UUID) string {
+	return "product:" + id.String()
+}
+
+func categoryTag(id uuid.UUID) string {
+	return "category:" + id.String()
+}
+
+func sellerTag(id uuid.UUID) string {
+	return "seller:" + id.String()
+}
+
+func listCacheKey(filter domain.ProductFilter) string {
+	return "products:list:" + hashKey(filter)
+}
+
+func searchCacheKey(query string, filter domain.ProductFilter) string {
+	return "products:search:" + hashKey(struct {
+		Query  string
+		Filter domain.ProductFilter
+	}{query, filter})
+}
+
+func hashKey(v interface{}) string {
+	raw, _ := json.Marshal(v)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalizeFilter rewrites equivalent filters to the same value so they
+// share a cache entry. The normalized filter is also what gets queried, so
+// it must not change which rows are returned.
+func normalizeFilter(filter domain.ProductFilter) domain.ProductFilter {
+	filter.Status = strings.TrimSpace(filter.Status)
+	filter.SortBy = strings.TrimSpace(filter.SortBy)
+
+	if filter.SortBy == "" {
+		filter.SortOrder = ""
+	} else if strings.EqualFold(filter.SortOrder, "desc") {
+		filter.SortOrder = "desc"
+	} else {
+		filter.SortOrder = "asc"
+	}
+
+	if filter.Page <= 0 || filter.Limit <= 0 {
+		filter.Page, filter.Limit = 0, 0
+	}
+
+	return filter
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+// pageTags returns the tags a cached list page is invalidated by: the scope
+// it was queried with plus every product, category and seller it contains.
+func pageTags(filter domain.ProductFilter, products []*domain.ProductWithVariants) []string {
+	seen := make(map[string]struct{})
+	var tags []string
+	add := func(tag string) {
+		if _, ok := seen[tag]; !ok {
+			seen[tag] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+
+	if filter.CategoryID != nil {
+		add(categoryTag(*filter.CategoryID))
+	} else {
+		add(allProductsTag)
+	}
+	if filter.SellerID != nil {
+		add(sellerTag(*filter.SellerID))
+	}
+
+	for _, product := range products {
+		add(productTag(product.ID))
+		add(categoryTag(product.CategoryID))
+		for _, variant := range product.Variants {
+			add(sellerTag(variant.SellerID))
+		}
+	}
+
+	return tags
+}
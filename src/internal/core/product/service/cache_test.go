@@ -0,0 +1,132 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/product/domain"
+	"reflect"
+	"testing"
+)
+
+func TestPageTags(t *testing.T) {
+	category, otherCategory := uuid.New(), uuid.New()
+	seller, otherSeller := uuid.New(), uuid.New()
+	first := &domain.ProductWithVariants{
+		Product:  &domain.Product{ID: uuid.New(), CategoryID: category},
+		Variants: []*domain.ProductVariant{{SellerID: seller}, {SellerID: otherSeller}},
+	}
+	second := &domain.ProductWithVariants{
+		Product:  &domain.Product{ID: uuid.New(), CategoryID: otherCategory},
+		Variants: []*domain.ProductVariant{{SellerID: seller}},
+	}
+
+	tests := []struct {
+		name     string
+		filter   domain.ProductFilter
+		products []*domain.ProductWithVariants
+		want     []string
+	}{
+		{
+			name: "empty unscoped page",
+			want: []string{allProductsTag},
+		},
+		{
+			name:   "empty category page",
+			filter: domain.ProductFilter{CategoryID: &category},
+			want:   []string{categoryTag(category)},
+		},
+		{
+			name:   "seller page",
+			filter: domain.ProductFilter{SellerID: &seller},
+			want:   []string{allProductsTag, sellerTag(seller)},
+		},
+		{
+			name:     "tags of listed products, each once",
+			products: []*domain.ProductWithVariants{first, second},
+			want: []string{
+				allProductsTag,
+				productTag(first.ID), categoryTag(category), sellerTag(seller), sellerTag(otherSeller),
+				productTag(second.ID), categoryTag(otherCategory),
+			},
+		},
+		{
+			name:     "category scope is not repeated by its products",
+			filter:   domain.ProductFilter{CategoryID: &category},
+			products: []*domain.ProductWithVariants{first},
+			want:     []string{categoryTag(category), productTag(first.ID), sellerTag(seller), sellerTag(otherSeller)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageTags(tt.filter, tt.products); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"shoes", "shoes"},
+		{"  Shoes ", "shoes"},
+		{"RED Shoes", "red shoes"},
+		{"", ""},
+		{" \t", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeQuery(tt.query); got != tt.want {
+			t.Errorf("normalizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFilterSharesCacheKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b domain.ProductFilter
+	}{
+		{
+			name: "sort order without a sort field",
+			a:    domain.ProductFilter{SortOrder: "desc"},
+			b:    domain.ProductFilter{},
+		},
+		{
+			name: "sort order case",
+			a:    domain.ProductFilter{SortBy: "price", SortOrder: "DESC"},
+			b:    domain.ProductFilter{SortBy: "price", SortOrder: "desc"},
+		},
+		{
+			name: "default sort order",
+			a:    domain.ProductFilter{SortBy: "price"},
+			b:    domain.ProductFilter{SortBy: "price", SortOrder: "asc"},
+		},
+		{
+			name: "surrounding spaces",
+			a:    domain.ProductFilter{Status: " active ", SortBy: " price"},
+			b:    domain.ProductFilter{Status: "active", SortBy: "price", SortOrder: "asc"},
+		},
+		{
+			name: "unpaginated",
+			a:    domain.ProductFilter{Page: 3},
+			b:    domain.ProductFilter{Limit: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := listCacheKey(normalizeFilter(tt.a)), listCacheKey(normalizeFilter(tt.b))
+			if a != b {
+				t.Errorf("keys differ: %s and %s", a, b)
+			}
+		})
+	}
+
+	paged := listCacheKey(normalizeFilter(domain.ProductFilter{Page: 1, Limit: 20}))
+	if other := listCacheKey(normalizeFilter(domain.ProductFilter{Page: 2, Limit: 20})); paged == other {
+		t.Error("different pages share a key")
+	}
+}
@@ -2,33 +2,51 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"golang_marketplace/src/internal/core/product/domain"
 	"golang_marketplace/src/internal/platform/cache"
 	"golang_marketplace/src/pkg/validator"
 	"gorm.io/gorm"
-	"time"
+	"log/slog"
 )
 
+// CacheOptions sets how long single products and list pages are cached.
+type CacheOptions struct {
+	Product cache.LoaderOptions
+	List    cache.LoaderOptions
+}
+
 type productService struct {
 	productRepo  domain.ProductRepository
 	variantRepo  domain.ProductVariantRepository
 	categoryRepo domain.CategoryRepository
-	cache        *cache.Cache
+	sellerRepo   domain.SellerRepository
+	cache        cache.Cache
+	loader       *cache.Loader
+	listLoader   *cache.Loader
+	logger       *slog.Logger
 }
 
 func NewProductService(
 	productRepo domain.ProductRepository,
 	variantRepo domain.ProductVariantRepository,
 	categoryRepo domain.CategoryRepository,
-	cache *cache.Cache,
+	sellerRepo domain.SellerRepository,
+	store cache.Cache,
+	cacheOpts CacheOptions,
+	logger *slog.Logger,
 ) domain.ProductService {
 	return &productService{
 		productRepo:  productRepo,
 		variantRepo:  variantRepo,
 		categoryRepo: categoryRepo,
-		cache:        cache,
+		sellerRepo:   sellerRepo,
+		cache:        store,
+		loader:       cache.NewLoader(store, cacheOpts.Product),
+		listLoader:   cache.NewLoader(store, cacheOpts.List),
+		logger:       logger,
 	}
 }
 
@@ -63,34 +81,45 @@ func (s *productService) CreateProduct(ctx context.Context, req domain.CreatePro
 		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
+	s.invalidate(ctx, allProductsTag, categoryTag(product.CategoryID))
+
 	return product, nil
 }
 
 func (s *productService) GetProduct(ctx context.Context, id uuid.UUID) (*domain.ProductWithVariants, error) {
-	cacheKey := fmt.Sprintf("product:%s", id.String())
-	var cached domain.ProductWithVariants
-	if err := s.cache.Get(cacheKey, &cached); err == nil {
-		return &cached, nil
-	}
+	var result domain.ProductWithVariants
+	// the entry is refilled right after writes invalidate it, which a lagging
+	// replica would answer with the old row for as long as it is cached
+	err := s.loader.Load(ctx, productCacheKey(id), &result, func(ctx context.Context) (interface{}, error) {
+		product, err := s.productRepo.GetByIDFromPrimary(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, cache.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	product, err := s.productRepo.GetByID(ctx, id)
-	if err != nil {
+		variants, err := s.variantRepo.GetByProductIDFromPrimary(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product variants: %w", err)
+		}
+
+		return cache.Tagged{
+			Value: &domain.ProductWithVariants{
+				Product:  product,
+				Variants: variants,
+			},
+			Tags: []string{productTag(id)},
+		}, nil
+	})
+	if errors.Is(err, cache.ErrNotFound) {
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
-
-	variants, err := s.variantRepo.GetByProductID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get product variants: %w", err)
-	}
-
-	result := &domain.ProductWithVariants{
-		Product:  product,
-		Variants: variants,
+		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
 
-	s.cache.Set(cacheKey, result, 5*time.Minute)
-
-	return result, nil
+	return &result, nil
 }
 
 func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req domain.UpdateProductRequest) (*domain.Product, error) {
@@ -98,10 +127,11 @@ func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req do
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	product, err := s.productRepo.GetByID(ctx, id)
+	product, err := s.productRepo.GetByIDFromPrimary(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
+	previousCategoryID := product.CategoryID
 
 	if req.Name != nil {
 		product.Name = *req.Name
@@ -111,7 +141,7 @@ func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req do
 	}
 	if req.CategoryID != nil {
 		if _, err := s.categoryRepo.GetByID(ctx, *req.CategoryID); err != nil {
-			return nil, fmt.Errorf("category not found: %w", *req.CategoryID)
+			return nil, fmt.Errorf("category %s not found: %w", *req.CategoryID, err)
 		}
 		product.CategoryID = *req.CategoryID
 	}
@@ -129,55 +159,74 @@ func (s *productService) UpdateProduct(ctx context.Context, id uuid.UUID, req do
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("product:%s", id.String())
-	_ = s.cache.Delete(cacheKey)
+	s.invalidate(ctx,
+		productTag(id),
+		allProductsTag,
+		categoryTag(previousCategoryID),
+		categoryTag(product.CategoryID),
+	)
 
 	return product, nil
 }
 
 func (s *productService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	tags := []string{productTag(id), allProductsTag}
+	if product, err := s.productRepo.GetByID(ctx, id); err == nil {
+		tags = append(tags, categoryTag(product.CategoryID))
+	}
+
 	if err := s.productRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("product:%s", id.String())
-	_ = s.cache.Delete(cacheKey)
+	s.invalidate(ctx, tags...)
 
 	return nil
 }
 
 func (s *productService) ListProducts(ctx context.Context, filter domain.ProductFilter) ([]*domain.ProductWithVariants, int64, error) {
-	products, total, err := s.productRepo.List(ctx, filter)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list products: %w", err)
-	}
+	filter = normalizeFilter(filter)
 
-	var result []*domain.ProductWithVariants
-	for _, product := range products {
-		variants, err := s.variantRepo.GetByProductID(ctx, product.ID)
+	var page productPage
+	err := s.listLoader.Load(ctx, listCacheKey(filter), &page, func(ctx context.Context) (interface{}, error) {
+		products, total, err := s.productRepo.List(ctx, filter)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to get variants for product %s: %w", product.ID, err)
+			return nil, err
 		}
-		result = append(result, &domain.ProductWithVariants{
-			Product:  product,
-			Variants: variants,
-		})
+		return s.newPage(ctx, filter, products, total)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list products: %w", err)
 	}
 
-	return result, total, nil
+	return page.Products, page.Total, nil
 }
 
 func (s *productService) SearchProducts(ctx context.Context, query string, filter domain.ProductFilter) ([]*domain.ProductWithVariants, int64, error) {
-	products, total, err := s.productRepo.Search(ctx, query, filter)
+	query = normalizeQuery(query)
+	filter = normalizeFilter(filter)
+
+	var page productPage
+	err := s.listLoader.Load(ctx, searchCacheKey(query, filter), &page, func(ctx context.Context) (interface{}, error) {
+		products, total, err := s.productRepo.Search(ctx, query, filter)
+		if err != nil {
+			return nil, err
+		}
+		return s.newPage(ctx, filter, products, total)
+	})
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to search products: %w", err)
 	}
 
+	return page.Products, page.Total, nil
+}
+
+func (s *productService) newPage(ctx context.Context, filter domain.ProductFilter, products []*domain.Product, total int64) (cache.Tagged, error) {
 	var result []*domain.ProductWithVariants
 	for _, product := range products {
 		variants, err := s.variantRepo.GetByProductID(ctx, product.ID)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to get variants for product %s: %w", product.ID, err)
+			return cache.Tagged{}, fmt.Errorf("failed to get variants for product %s: %w", product.ID, err)
 		}
 		result = append(result, &domain.ProductWithVariants{
 			Product:  product,
@@ -185,7 +234,10 @@ func (s *productService) SearchProducts(ctx context.Context, query string, filte
 		})
 	}
 
-	return result, total, nil
+	return cache.Tagged{
+		Value: productPage{Products: result, Total: total},
+		Tags:  pageTags(filter, result),
+	}, nil
 }
 
 func (s *productService) AddProductVariant(ctx context.Context, req domain.CreateVariantRequest) (*domain.ProductVariant, error) {
@@ -193,7 +245,8 @@ func (s *productService) AddProductVariant(ctx context.Context, req domain.Creat
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	if _, err := s.productRepo.GetByID(ctx, req.ProductID); err != nil {
+	product, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
 		return nil, fmt.Errorf("product not found: %w", err)
 	}
 
@@ -215,9 +268,19 @@ func (s *productService) AddProductVariant(ctx context.Context, req domain.Creat
 		return nil, fmt.Errorf("failed to create variant: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("product:%s", req.ProductID.String())
-	s.cache.Delete(cacheKey)
+	s.invalidateVariant(ctx, variant, product)
+
+	return variant, nil
+}
 
+func (s *productService) GetProductVariant(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	variant, err := s.variantRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
 	return variant, nil
 }
 
@@ -226,7 +289,7 @@ func (s *productService) UpdateProductVariant(ctx context.Context, id uuid.UUID,
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
-	variant, err := s.variantRepo.GetByID(ctx, id)
+	variant, err := s.variantRepo.GetByIDFromPrimary(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("variant not found: %w", err)
 	}
@@ -255,8 +318,7 @@ func (s *productService) UpdateProductVariant(ctx context.Context, id uuid.UUID,
 		return nil, fmt.Errorf("failed to update variant: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("product:%s", variant.ProductID.String())
-	_ = s.cache.Delete(cacheKey)
+	s.invalidateVariant(ctx, variant, nil)
 
 	return variant, nil
 }
@@ -271,8 +333,7 @@ func (s *productService) DeleteProductVariant(ctx context.Context, id uuid.UUID)
 		return fmt.Errorf("failed to delete variant: %w", err)
 	}
 
-	cacheKey := fmt.Sprintf("product:%s", variant.ProductID.String())
-	_ = s.cache.Delete(cacheKey)
+	s.invalidateVariant(ctx, variant, nil)
 
 	return nil
 }
@@ -294,15 +355,15 @@ func (s *productService) GetVariantsBySeller(ctx context.Context, sellerID uuid.
 }
 
 func (s *productService) UpdateStock(ctx context.Context, variantID uuid.UUID, quantity int) error {
-	if err := s.variantRepo.UpdateStock(ctx, variantID, quantity); err != nil {
+	variant, err := s.variantRepo.UpdateStock(ctx, variantID, quantity)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrVariantNotFound
+	}
+	if err != nil {
 		return fmt.Errorf("failed to update stock: %w", err)
 	}
 
-	variant, err := s.variantRepo.GetByID(ctx, variantID)
-	if err == nil {
-		cacheKey := fmt.Sprintf("product:%s", variant.ProductID.String())
-		_ = s.cache.Delete(cacheKey)
-	}
+	s.invalidateVariant(ctx, variant, nil)
 
 	return nil
 }
@@ -315,3 +376,83 @@ func (s *productService) CheckStock(ctx context.Context, variantID uuid.UUID, qu
 
 	return variant.Stock >= quantity, nil
 }
+
+func (s *productService) CreateCategory(ctx context.Context, req domain.CreateCategoryRequest) (*domain.Category, error) {
+	if err := validator.ValidateStruct(req); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	if req.ParentID != nil {
+		if _, err := s.categoryRepo.GetByID(ctx, *req.ParentID); err != nil {
+			return nil, fmt.Errorf("parent category %s not found: %w", *req.ParentID, err)
+		}
+	}
+
+	category := &domain.Category{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+		IsActive: true,
+	}
+
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (s *productService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+func (s *productService) GetSellerByUserID(ctx context.Context, userID uint) (*domain.Seller, error) {
+	seller, err := s.sellerRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrSellerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller: %w", err)
+	}
+	return seller, nil
+}
+
+func (s *productService) AnonymizeSeller(ctx context.Context, userID uint) error {
+	if err := s.sellerRepo.AnonymizeByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to anonymize seller: %w", err)
+	}
+	return nil
+}
+
+// invalidateVariant refreshes what a new or changed variant can affect.
+// Besides the pages holding its product, every page of the product's
+// category and every unscoped page may now include it, for example when
+// filtered by price or stock. product is looked up when nil; if that fails
+// the category's pages are left to expire.
+func (s *productService) invalidateVariant(ctx context.Context, variant *domain.ProductVariant, product *domain.Product) {
+	tags := []string{productTag(variant.ProductID), sellerTag(variant.SellerID), allProductsTag}
+
+	if product == nil {
+		var err error
+		product, err = s.productRepo.GetByIDFromPrimary(ctx, variant.ProductID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to get product for cache invalidation", "product_id", variant.ProductID, "error", err)
+		}
+	}
+	if product != nil {
+		tags = append(tags, categoryTag(product.CategoryID))
+	}
+
+	s.invalidate(ctx, tags...)
+}
+
+// invalidate drops every cached entry carrying one of tags. A failure only
+// leaves entries to expire on their own, so it is logged rather than returned.
+func (s *productService) invalidate(ctx context.Context, tags ...string) {
+	if err := s.cache.InvalidateTags(ctx, tags...); err != nil {
+		s.logger.WarnContext(ctx, "failed to invalidate product cache", "tags", tags, "error", err)
+	}
+}
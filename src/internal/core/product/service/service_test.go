@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"gorm.io/gorm"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type stubProductRepo struct {
+	domain.ProductRepository
+	product *domain.Product
+}
+
+func (r *stubProductRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Product, error) {
+	if r.product == nil || r.product.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.product, nil
+}
+
+func (r *stubProductRepo) GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return r.GetByID(ctx, id)
+}
+
+type stubVariantRepo struct {
+	domain.ProductVariantRepository
+	variant *domain.ProductVariant
+}
+
+func (r *stubVariantRepo) Create(_ context.Context, variant *domain.ProductVariant) error {
+	variant.ID = uuid.New()
+	r.variant = variant
+	return nil
+}
+
+func (r *stubVariantRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	if r.variant == nil || r.variant.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.variant, nil
+}
+
+func (r *stubVariantRepo) GetByIDFromPrimary(ctx context.Context, id uuid.UUID) (*domain.ProductVariant, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *stubVariantRepo) Update(context.Context, *domain.ProductVariant) error {
+	return nil
+}
+
+func (r *stubVariantRepo) UpdateStock(_ context.Context, id uuid.UUID, quantity int) (*domain.ProductVariant, error) {
+	if r.variant == nil || r.variant.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if r.variant.Stock+quantity < 0 {
+		return nil, domain.ErrInsufficientStock
+	}
+	r.variant.Stock += quantity
+	return r.variant, nil
+}
+
+func TestVariantChangesInvalidateListPages(t *testing.T) {
+	ctx := context.Background()
+	category := uuid.New()
+	product := &domain.Product{ID: uuid.New(), CategoryID: category, Status: "active"}
+	seller := uuid.New()
+	price := 20.0
+
+	tests := []struct {
+		name string
+		// lostProduct makes the product lookup fail, as if it was deleted
+		// concurrently
+		lostProduct bool
+		change      func(s domain.ProductService, variant *domain.ProductVariant) error
+		wantGone    []string
+	}{
+		{
+			name: "add variant",
+			change: func(s domain.ProductService, _ *domain.ProductVariant) error {
+				_, err := s.AddProductVariant(ctx, domain.CreateVariantRequest{ProductID: product.ID, SellerID: seller, Price: 10, Stock: 1})
+				return err
+			},
+			wantGone: []string{"category", "all", "product"},
+		},
+		{
+			name: "update variant price",
+			change: func(s domain.ProductService, variant *domain.ProductVariant) error {
+				_, err := s.UpdateProductVariant(ctx, variant.ID, domain.UpdateVariantRequest{Price: &price})
+				return err
+			},
+			wantGone: []string{"category", "all", "product"},
+		},
+		{
+			name: "adjust stock",
+			change: func(s domain.ProductService, variant *domain.ProductVariant) error {
+				return s.UpdateStock(ctx, variant.ID, 3)
+			},
+			wantGone: []string{"category", "all", "product"},
+		},
+		{
+			name:        "adjust stock of a product that cannot be loaded",
+			lostProduct: true,
+			change: func(s domain.ProductService, variant *domain.ProductVariant) error {
+				return s.UpdateStock(ctx, variant.ID, 3)
+			},
+			wantGone: []string{"all", "product"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewMemoryCache(100)
+			products := &stubProductRepo{product: product}
+			variants := &stubVariantRepo{variant: &domain.ProductVariant{ID: uuid.New(), ProductID: product.ID, SellerID: seller, Price: 10}}
+			svc := NewProductService(products, variants, nil, nil, store, CacheOptions{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			pages := map[string]string{
+				"category": categoryTag(category),
+				"all":      allProductsTag,
+				"product":  productTag(product.ID),
+				"other":    categoryTag(uuid.New()),
+			}
+			for key, tag := range pages {
+				if err := store.SetWithTags(ctx, key, "page", time.Hour, tag); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.lostProduct {
+				products.product = nil
+			}
+			if err := tt.change(svc, variants.variant); err != nil {
+				t.Fatal(err)
+			}
+
+			gone := make(map[string]bool)
+			for _, key := range tt.wantGone {
+				gone[key] = true
+			}
+			for key := range pages {
+				exists, err := store.Exists(ctx, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if exists == gone[key] {
+					t.Errorf("page %q cached = %v, want %v", key, exists, !gone[key])
+				}
+			}
+		})
+	}
+}
@@ -8,5 +8,5 @@ import (
 type sellerService struct {
 	repo        domain.SellerRepository
 	productRepo domain.SellerProductRepository
-	cache       cache.RedisCache
+	cache       cache.Cache
 }
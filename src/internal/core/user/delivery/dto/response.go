@@ -0,0 +1,26 @@
+package dto
+
+import (
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type AddressListResponse struct {
+	Addresses []*domain.Address `json:"addresses"`
+}
+
+type DataExportListResponse struct {
+	Exports []*domain.DataExport `json:"exports"`
+}
+
+type WishlistListResponse struct {
+	Wishlists []*domain.Wishlist `json:"wishlists"`
+}
+
+type RecentlyViewedResponse struct {
+	Products []*productdomain.ProductWithVariants `json:"products"`
+}
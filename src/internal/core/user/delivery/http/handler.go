@@ -0,0 +1,904 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/user/delivery/dto"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/pkg/validator"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type UserHandler struct {
+	service domain.UserService
+	logger  *slog.Logger
+}
+
+func NewUserHandler(service domain.UserService, logger *slog.Logger) *UserHandler {
+	return &UserHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetMe godoc
+// @Summary Get the current user
+// @Description Get the caller's account
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.User
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	user, err := h.service.GetUserByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to get user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateMe godoc
+// @Summary Update the current user
+// @Description Change the caller's name. Omitted fields are left as they are.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body domain.UpdateUserInput true "Fields to change"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me [put]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.UpdateUserInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to update user", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteMe godoc
+// @Summary Delete the current user
+// @Description Schedule the caller's account for deletion and sign them out everywhere. Once the grace period ends, personal data is scrubbed; orders are kept anonymized. Logging in again before then allows cancelling.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} domain.User
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	user, err := h.service.RequestDeletion(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to request deletion", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, user)
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description Keep the caller's account, which was scheduled for deletion
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.User
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/me/deletion [delete]
+func (h *UserHandler) CancelDeletion(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	user, err := h.service.CancelDeletion(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to cancel deletion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetCustomerProfile godoc
+// @Summary Get the customer profile
+// @Description Get the caller's customer profile; it is empty until first updated
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.CustomerProfile
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/customer-profile [get]
+func (h *UserHandler) GetCustomerProfile(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	profile, err := h.service.GetCustomerProfile(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to get customer profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateCustomerProfile godoc
+// @Summary Update the customer profile
+// @Description Change the caller's customer profile. Omitted fields are left as they are.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body domain.UpdateCustomerProfileInput true "Fields to change"
+// @Success 200 {object} domain.CustomerProfile
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/customer-profile [put]
+func (h *UserHandler) UpdateCustomerProfile(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.UpdateCustomerProfileInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profile, err := h.service.UpdateCustomerProfile(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to update customer profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetSellerProfile godoc
+// @Summary Get the seller profile
+// @Description Get the profile of the seller linked to the caller
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.SellerProfile
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/seller-profile [get]
+func (h *UserHandler) GetSellerProfile(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	profile, err := h.service.GetSellerProfile(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to get seller profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateSellerProfile godoc
+// @Summary Update the seller profile
+// @Description Change the profile of the seller linked to the caller. Omitted fields are left as they are.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body domain.UpdateSellerProfileInput true "Fields to change"
+// @Success 200 {object} domain.SellerProfile
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/seller-profile [put]
+func (h *UserHandler) UpdateSellerProfile(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.UpdateSellerProfileInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	profile, err := h.service.UpdateSellerProfile(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to update seller profile", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListAddresses godoc
+// @Summary List addresses
+// @Description List the caller's saved addresses, defaults first
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AddressListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/addresses [get]
+func (h *UserHandler) ListAddresses(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	addresses, err := h.service.ListAddresses(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to list addresses", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AddressListResponse{Addresses: addresses})
+}
+
+// CreateAddress godoc
+// @Summary Add an address
+// @Description Save a new address. The first one becomes the default shipping and billing address; setting a default flag takes it from the previous default.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param address body domain.AddressInput true "Address"
+// @Success 201 {object} domain.Address
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/me/addresses [post]
+func (h *UserHandler) CreateAddress(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.AddressInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := h.service.CreateAddress(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to create address", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// GetAddress godoc
+// @Summary Get an address
+// @Description Get one of the caller's saved addresses
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Success 200 {object} domain.Address
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/addresses/{id} [get]
+func (h *UserHandler) GetAddress(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid address ID"})
+		return
+	}
+
+	address, err := h.service.GetAddress(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		h.handleError(c, "failed to get address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// UpdateAddress godoc
+// @Summary Replace an address
+// @Description Replace one of the caller's saved addresses. Orders already placed keep the address they were placed with.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Param address body domain.AddressInput true "Address"
+// @Success 200 {object} domain.Address
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/addresses/{id} [put]
+func (h *UserHandler) UpdateAddress(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid address ID"})
+		return
+	}
+
+	var req domain.AddressInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	address, err := h.service.UpdateAddress(c.Request.Context(), claims.UserID, id, req)
+	if err != nil {
+		h.handleError(c, "failed to update address", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// DeleteAddress godoc
+// @Summary Delete an address
+// @Description Delete one of the caller's saved addresses. A default flag it held moves to the most recently updated remaining address.
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/addresses/{id} [delete]
+func (h *UserHandler) DeleteAddress(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid address ID"})
+		return
+	}
+
+	if err := h.service.DeleteAddress(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to delete address", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWishlists godoc
+// @Summary List wishlists
+// @Description List the caller's wishlists with their item counts
+// @Tags wishlists
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.WishlistListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/wishlists [get]
+func (h *UserHandler) ListWishlists(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	wishlists, err := h.service.ListWishlists(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to list wishlists", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WishlistListResponse{Wishlists: wishlists})
+}
+
+// CreateWishlist godoc
+// @Summary Create a wishlist
+// @Description Create an empty named wishlist
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param wishlist body domain.WishlistInput true "Wishlist"
+// @Success 201 {object} domain.Wishlist
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/me/wishlists [post]
+func (h *UserHandler) CreateWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	var req domain.WishlistInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wishlist, err := h.service.CreateWishlist(c.Request.Context(), claims.UserID, req)
+	if err != nil {
+		h.handleError(c, "failed to create wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, wishlist)
+}
+
+// GetWishlist godoc
+// @Summary Get a wishlist
+// @Description Get one of the caller's wishlists. Items carry current prices and stock, and flag price drops and restocks since they were saved.
+// @Tags wishlists
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} domain.Wishlist
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id} [get]
+func (h *UserHandler) GetWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	wishlist, err := h.service.GetWishlist(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		h.handleError(c, "failed to get wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// RenameWishlist godoc
+// @Summary Rename a wishlist
+// @Description Change the name of one of the caller's wishlists
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Param wishlist body domain.WishlistInput true "Wishlist"
+// @Success 200 {object} domain.Wishlist
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id} [put]
+func (h *UserHandler) RenameWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	var req domain.WishlistInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	wishlist, err := h.service.RenameWishlist(c.Request.Context(), claims.UserID, id, req)
+	if err != nil {
+		h.handleError(c, "failed to rename wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// DeleteWishlist godoc
+// @Summary Delete a wishlist
+// @Description Delete one of the caller's wishlists with its items
+// @Tags wishlists
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id} [delete]
+func (h *UserHandler) DeleteWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	if err := h.service.DeleteWishlist(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to delete wishlist", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddWishlistItem godoc
+// @Summary Add to a wishlist
+// @Description Save a product, or one of its variants, to one of the caller's wishlists
+// @Tags wishlists
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Param item body domain.AddWishlistItemInput true "Product to save"
+// @Success 201 {object} domain.WishlistItem
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id}/items [post]
+func (h *UserHandler) AddWishlistItem(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	var req domain.AddWishlistItemInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item, err := h.service.AddWishlistItem(c.Request.Context(), claims.UserID, id, req)
+	if err != nil {
+		h.handleError(c, "failed to add wishlist item", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// RemoveWishlistItem godoc
+// @Summary Remove from a wishlist
+// @Description Remove an item from one of the caller's wishlists
+// @Tags wishlists
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Param itemId path string true "Item ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id}/items/{itemId} [delete]
+func (h *UserHandler) RemoveWishlistItem(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid item ID"})
+		return
+	}
+
+	if err := h.service.RemoveWishlistItem(c.Request.Context(), claims.UserID, id, itemID); err != nil {
+		h.handleError(c, "failed to remove wishlist item", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddWishlistItemToCart godoc
+// @Summary Add a wishlist item to the cart
+// @Description Put a saved item in the caller's cart. Items saved without a variant need one chosen.
+// @Tags wishlists
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Param itemId path string true "Item ID"
+// @Param cart body domain.WishlistCartInput false "Variant and quantity, 1 by default"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 501 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id}/items/{itemId}/cart [post]
+func (h *UserHandler) AddWishlistItemToCart(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid item ID"})
+		return
+	}
+
+	var req domain.WishlistCartInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	if err := h.service.AddWishlistItemToCart(c.Request.Context(), claims.UserID, id, itemID, req); err != nil {
+		h.handleError(c, "failed to add wishlist item to cart", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ShareWishlist godoc
+// @Summary Share a wishlist
+// @Description Give one of the caller's wishlists a share token. Anyone can read it at /wishlists/shared/{share_token} until it is unshared.
+// @Tags wishlists
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} domain.Wishlist
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id}/share [put]
+func (h *UserHandler) ShareWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	wishlist, err := h.service.ShareWishlist(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		h.handleError(c, "failed to share wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// UnshareWishlist godoc
+// @Summary Stop sharing a wishlist
+// @Description Break the share link of one of the caller's wishlists
+// @Tags wishlists
+// @Security BearerAuth
+// @Param id path string true "Wishlist ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/wishlists/{id}/share [delete]
+func (h *UserHandler) UnshareWishlist(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid wishlist ID"})
+		return
+	}
+
+	if err := h.service.UnshareWishlist(c.Request.Context(), claims.UserID, id); err != nil {
+		h.handleError(c, "failed to unshare wishlist", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSharedWishlist godoc
+// @Summary Get a shared wishlist
+// @Description Read a wishlist through its share token
+// @Tags wishlists
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} domain.SharedWishlist
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /wishlists/shared/{token} [get]
+func (h *UserHandler) GetSharedWishlist(c *gin.Context) {
+	wishlist, err := h.service.GetSharedWishlist(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, "failed to get shared wishlist", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wishlist)
+}
+
+// ListRecentlyViewed godoc
+// @Summary List recently viewed products
+// @Description List the active products the caller viewed, most recent first
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of products" default(20)
+// @Success 200 {object} dto.RecentlyViewedResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/recently-viewed [get]
+func (h *UserHandler) ListRecentlyViewed(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	products, err := h.service.ListRecentlyViewed(c.Request.Context(), claims.UserID, limit)
+	if err != nil {
+		h.handleError(c, "failed to list recently viewed products", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RecentlyViewedResponse{Products: products})
+}
+
+// ClearRecentlyViewed godoc
+// @Summary Clear recently viewed products
+// @Description Forget the products the caller viewed
+// @Tags users
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/recently-viewed [delete]
+func (h *UserHandler) ClearRecentlyViewed(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	if err := h.service.ClearRecentlyViewed(c.Request.Context(), claims.UserID); err != nil {
+		h.handleError(c, "failed to clear recently viewed products", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestDataExport godoc
+// @Summary Export my data
+// @Description Start preparing a zip archive of everything held about the caller. The caller is notified with a download link when it is ready.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} domain.DataExport
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Router /users/me/exports [post]
+func (h *UserHandler) RequestDataExport(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	export, err := h.service.RequestDataExport(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to request data export", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// ListDataExports godoc
+// @Summary List my data exports
+// @Description List the caller's data exports, newest first. Ready ones carry a download link.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.DataExportListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/me/exports [get]
+func (h *UserHandler) ListDataExports(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	exports, err := h.service.ListDataExports(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleError(c, "failed to list data exports", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataExportListResponse{Exports: exports})
+}
+
+// GetDataExport godoc
+// @Summary Get a data export
+// @Description Get one of the caller's data exports, to see whether it is ready
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} domain.DataExport
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/me/exports/{id} [get]
+func (h *UserHandler) GetDataExport(c *gin.Context) {
+	claims, _ := authdomain.ClaimsFromContext(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid export ID"})
+		return
+	}
+
+	export, err := h.service.GetDataExport(c.Request.Context(), claims.UserID, id)
+	if err != nil {
+		h.handleError(c, "failed to get data export", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// DownloadDataExport godoc
+// @Summary Download a data export
+// @Description Download a data export archive through the signed link from the notification or the export
+// @Tags users
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param expires query int true "Link expiry as a Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Router /exports/{id}/download [get]
+func (h *UserHandler) DownloadDataExport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid export ID"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: "invalid expires"})
+		return
+	}
+
+	export, path, err := h.service.OpenDataExport(c.Request.Context(), id, expires, c.Query("signature"))
+	if err != nil {
+		h.handleError(c, "failed to open data export", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, "data-export-"+export.CompletedAt.Format(time.DateOnly)+".zip")
+}
+
+func (h *UserHandler) handleError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, validator.ErrValidation):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrNotSeller), errors.Is(err, domain.ErrAddressNotFound),
+		errors.Is(err, domain.ErrExportNotFound), errors.Is(err, domain.ErrWishlistNotFound),
+		errors.Is(err, domain.ErrWishlistItemNotFound), errors.Is(err, domain.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrInvalidExportURL):
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrTooManyAddresses), errors.Is(err, domain.ErrExportInProgress), errors.Is(err, domain.ErrNoPendingDeletion),
+		errors.Is(err, domain.ErrTooManyWishlists), errors.Is(err, domain.ErrWishlistFull), errors.Is(err, domain.ErrAlreadyInWishlist),
+		errors.Is(err, domain.ErrProductUnavailable), errors.Is(err, domain.ErrOutOfStock):
+		c.JSON(http.StatusConflict, dto.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, domain.ErrCartUnavailable):
+		c.JSON(http.StatusNotImplemented, dto.ErrorResponse{Error: err.Error()})
+	default:
+		h.logger.ErrorContext(c.Request.Context(), msg, "error", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "internal server error"})
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"log/slog"
+	"net/http"
+)
+
+// GuestSessionHeader carries a client-generated UUID that identifies a guest
+// browsing without logging in.
+const GuestSessionHeader = "X-Guest-Session"
+
+// TrackProductViews records a successful product page view for the caller,
+// who is identified by optional authentication in front of it or by their
+// guest session. API keys act for integrations, so their views are not
+// recorded.
+func TrackProductViews(service domain.UserService, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() != http.StatusOK {
+			return
+		}
+		productID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return
+		}
+
+		var viewer domain.Viewer
+		if guest, err := uuid.Parse(c.GetHeader(GuestSessionHeader)); err == nil {
+			viewer.GuestSession = guest.String()
+		}
+		if claims, ok := authdomain.ClaimsFromContext(c.Request.Context()); ok {
+			if claims.APIKeyID != nil {
+				return
+			}
+			viewer.UserID = claims.UserID
+		}
+		if viewer.UserID == 0 && viewer.GuestSession == "" {
+			return
+		}
+
+		if err := service.RecordProductView(c.Request.Context(), viewer, productID); err != nil {
+			logger.WarnContext(c.Request.Context(), "failed to record product view", "product_id", productID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	authhttp "golang_marketplace/src/internal/core/auth/delivery/http"
+	authdomain "golang_marketplace/src/internal/core/auth/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"log/slog"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, service domain.UserService, authenticate gin.HandlerFunc, logger *slog.Logger) {
+	handler := NewUserHandler(service, logger)
+
+	// API keys act for a seller's integration, not for the person
+	me := router.Group("/users/me", authenticate, authhttp.RequireSession())
+	{
+		me.GET("", handler.GetMe)
+		me.PUT("", handler.UpdateMe)
+		me.DELETE("", handler.DeleteMe)
+		me.DELETE("/deletion", handler.CancelDeletion)
+		me.GET("/customer-profile", handler.GetCustomerProfile)
+		me.PUT("/customer-profile", handler.UpdateCustomerProfile)
+
+		me.GET("/addresses", handler.ListAddresses)
+		me.POST("/addresses", handler.CreateAddress)
+		me.GET("/addresses/:id", handler.GetAddress)
+		me.PUT("/addresses/:id", handler.UpdateAddress)
+		me.DELETE("/addresses/:id", handler.DeleteAddress)
+
+		me.GET("/wishlists", handler.ListWishlists)
+		me.POST("/wishlists", handler.CreateWishlist)
+		me.GET("/wishlists/:id", handler.GetWishlist)
+		me.PUT("/wishlists/:id", handler.RenameWishlist)
+		me.DELETE("/wishlists/:id", handler.DeleteWishlist)
+		me.POST("/wishlists/:id/items", handler.AddWishlistItem)
+		me.DELETE("/wishlists/:id/items/:itemId", handler.RemoveWishlistItem)
+		me.POST("/wishlists/:id/items/:itemId/cart", handler.AddWishlistItemToCart)
+		me.PUT("/wishlists/:id/share", handler.ShareWishlist)
+		me.DELETE("/wishlists/:id/share", handler.UnshareWishlist)
+
+		me.GET("/recently-viewed", handler.ListRecentlyViewed)
+		me.DELETE("/recently-viewed", handler.ClearRecentlyViewed)
+
+		me.GET("/exports", handler.ListDataExports)
+		me.POST("/exports", handler.RequestDataExport)
+		me.GET("/exports/:id", handler.GetDataExport)
+
+		sellerOnly := authhttp.RequireRole(authdomain.RoleSeller)
+		me.GET("/seller-profile", sellerOnly, handler.GetSellerProfile)
+		me.PUT("/seller-profile", sellerOnly, handler.UpdateSellerProfile)
+	}
+
+	// opened from the link in the notification, which carries its own signature
+	router.GET("/exports/:id/download", handler.DownloadDataExport)
+	router.GET("/wishlists/shared/:token", handler.GetSharedWishlist)
+}
@@ -0,0 +1,347 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	notificationdomain "golang_marketplace/src/internal/core/notification/domain"
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+	"time"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrNotSeller         = errors.New("user has no seller account")
+	ErrAddressNotFound   = errors.New("address not found")
+	ErrTooManyAddresses  = errors.New("address book is full")
+	ErrExportNotFound    = errors.New("data export not found")
+	ErrExportInProgress  = errors.New("a data export is already being prepared")
+	ErrInvalidExportURL  = errors.New("download link is invalid or has expired")
+	ErrNoPendingDeletion = errors.New("account is not scheduled for deletion")
+
+	ErrWishlistNotFound     = errors.New("wishlist not found")
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+	ErrTooManyWishlists     = errors.New("too many wishlists")
+	ErrWishlistFull         = errors.New("wishlist is full")
+	ErrAlreadyInWishlist    = errors.New("product is already in the wishlist")
+	ErrProductNotFound      = errors.New("product not found")
+	ErrProductUnavailable   = errors.New("product is not available")
+	ErrOutOfStock           = errors.New("not enough stock")
+	ErrCartUnavailable      = errors.New("cart is not available")
+)
+
+const (
+	// MaxAddresses is how many addresses one user can save.
+	MaxAddresses = 20
+	// MaxWishlists is how many wishlists one user can have, and
+	// MaxWishlistItems how many items each can hold.
+	MaxWishlists     = 20
+	MaxWishlistItems = 200
+)
+
+// User is the account as seen by its owner. Credentials are managed by the
+// auth module and never loaded here.
+type User struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// An account whose deletion was requested is anonymized once
+	// DeletionScheduledFor passes, unless the user cancels before then.
+	DeletionRequestedAt  *time.Time `json:"deletion_requested_at,omitempty"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty"`
+	AnonymizedAt         *time.Time `json:"-"`
+}
+
+// CustomerProfile holds what a user shares as a shopper. Users without a
+// stored profile get an empty one.
+type CustomerProfile struct {
+	UserID            uint       `json:"user_id" gorm:"primaryKey"`
+	Phone             string     `json:"phone"`
+	DateOfBirth       *time.Time `json:"date_of_birth,omitempty" gorm:"type:date"`
+	PreferredLanguage string     `json:"preferred_language"`
+	MarketingOptIn    bool       `json:"marketing_opt_in"`
+	// RecentlyViewedOptOut stops recording the products the user looks at.
+	RecentlyViewedOptOut bool      `json:"recently_viewed_opt_out"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// SellerProfile is the public face of a seller account. It belongs to the
+// user whose ID is in the seller's user_id.
+type SellerProfile struct {
+	UserID       uint      `json:"user_id" gorm:"primaryKey"`
+	DisplayName  string    `json:"display_name"`
+	Bio          string    `json:"bio"`
+	Website      string    `json:"website"`
+	SupportEmail string    `json:"support_email"`
+	SupportPhone string    `json:"support_phone"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Seller *SellerAccount `json:"seller,omitempty" gorm:"foreignKey:UserID;references:UserID"`
+}
+
+// SellerAccount is a read-only view of the seller linked to a user.
+type SellerAccount struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID      *uint     `json:"user_id,omitempty"`
+	CompanyName string    `json:"company_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	IsActive    bool      `json:"is_active"`
+}
+
+func (SellerAccount) TableName() string {
+	return "sellers"
+}
+
+// Address is an entry in a customer's address book. A user has at most one
+// default shipping and one default billing address.
+type Address struct {
+	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID            uint      `json:"user_id" gorm:"not null"`
+	Label             string    `json:"label"`
+	FullName          string    `json:"full_name"`
+	Phone             string    `json:"phone"`
+	Country           string    `json:"country"`
+	City              string    `json:"city"`
+	District          string    `json:"district"`
+	PostalCode        string    `json:"postal_code"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Snapshot copies the address for an order, so that later edits to the
+// address book do not change where past orders went.
+func (a *Address) Snapshot() *AddressSnapshot {
+	return &AddressSnapshot{
+		AddressID:  a.ID,
+		FullName:   a.FullName,
+		Phone:      a.Phone,
+		Country:    a.Country,
+		City:       a.City,
+		District:   a.District,
+		PostalCode: a.PostalCode,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+	}
+}
+
+// AddressSnapshot is an address as it was when an order was placed. It is
+// stored with the order as a JSON object.
+type AddressSnapshot struct {
+	// AddressID is the address book entry it was copied from, which may
+	// since have changed or been deleted.
+	AddressID  uuid.UUID `json:"address_id"`
+	FullName   string    `json:"full_name"`
+	Phone      string    `json:"phone,omitempty"`
+	Country    string    `json:"country"`
+	City       string    `json:"city"`
+	District   string    `json:"district,omitempty"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2,omitempty"`
+}
+
+func (a AddressSnapshot) Value() (driver.Value, error) {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (a *AddressSnapshot) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case []byte:
+		return json.Unmarshal(v, a)
+	default:
+		return fmt.Errorf("cannot scan %T into AddressSnapshot", src)
+	}
+}
+
+// AddressInput is used both to add an address and to replace one.
+type AddressInput struct {
+	Label    string `json:"label" validate:"max=50"`
+	FullName string `json:"full_name" validate:"required,max=200"`
+	Phone    string `json:"phone" validate:"omitempty,e164"`
+	// Country is an ISO 3166-1 alpha-2 code such as TR or DE.
+	Country           string `json:"country" validate:"required,iso3166_1_alpha2"`
+	City              string `json:"city" validate:"required,max=100"`
+	District          string `json:"district" validate:"max=100"`
+	PostalCode        string `json:"postal_code" validate:"omitempty,postcode_iso3166_alpha2_field=Country"`
+	Line1             string `json:"line1" validate:"required,max=255"`
+	Line2             string `json:"line2" validate:"max=255"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+// Wishlist is a named list of products a user saved. Sharing it gives it a
+// token that lets anyone read it.
+type Wishlist struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID     uint      `json:"user_id" gorm:"not null"`
+	Name       string    `json:"name"`
+	ShareToken *string   `json:"share_token,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	ItemCount int64           `json:"item_count" gorm:"->"`
+	Items     []*WishlistItem `json:"items,omitempty"`
+}
+
+// WishlistItem is a saved product, or one specific variant of it. The price
+// and stock seen when it was saved are kept to tell when it got cheaper or
+// came back in stock.
+type WishlistItem struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	WishlistID       uuid.UUID  `json:"wishlist_id" gorm:"type:uuid;not null"`
+	ProductID        uuid.UUID  `json:"product_id" gorm:"type:uuid;not null"`
+	VariantID        *uuid.UUID `json:"variant_id,omitempty" gorm:"type:uuid"`
+	PriceWhenAdded   *float64   `json:"price_when_added,omitempty"`
+	InStockWhenAdded bool       `json:"in_stock_when_added"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	// Filled in from the current catalog when the wishlist is read. For an
+	// item without a variant, price and stock are over all active variants.
+	Product      *productdomain.Product        `json:"product,omitempty" gorm:"-"`
+	Variant      *productdomain.ProductVariant `json:"variant,omitempty" gorm:"-"`
+	Available    bool                          `json:"available" gorm:"-"`
+	CurrentPrice *float64                      `json:"current_price,omitempty" gorm:"-"`
+	InStock      bool                          `json:"in_stock" gorm:"-"`
+	PriceDropped bool                          `json:"price_dropped" gorm:"-"`
+	BackInStock  bool                          `json:"back_in_stock" gorm:"-"`
+}
+
+// SharedWishlist is what a share link shows: the list without its owner's
+// account details.
+type SharedWishlist struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	OwnerName string          `json:"owner_name,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Items     []*WishlistItem `json:"items"`
+}
+
+// Catalog looks up the products that wishlists refer to. The product
+// module's service satisfies it.
+type Catalog interface {
+	GetProduct(ctx context.Context, id uuid.UUID) (*productdomain.ProductWithVariants, error)
+}
+
+// Cart takes items added from a wishlist. Carts are not kept by this
+// module; whichever module owns them registers one.
+type Cart interface {
+	AddItem(ctx context.Context, userID uint, variantID uuid.UUID, quantity int) error
+}
+
+// RecentList keeps recency lists: up to max distinct members per key,
+// newest first. Pushing a member already in a list moves it to the front,
+// and every push restarts ttl. The Redis cache implements it.
+type RecentList interface {
+	PushRecent(ctx context.Context, key, member string, max int, ttl time.Duration) error
+	Recent(ctx context.Context, key string, limit int) ([]string, error)
+	RemoveRecent(ctx context.Context, key string, members ...string) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Notifier tells users about their account, such as an export being ready,
+// on the channels they chose.
+type Notifier interface {
+	Notify(ctx context.Context, notification *notificationdomain.Notification) error
+}
+
+// Viewer is whoever looked at a product: a logged-in user, a guest known by
+// the session ID their client sends, or a user who browsed as that guest
+// before logging in.
+type Viewer struct {
+	UserID       uint
+	GuestSession string
+}
+
+// Data export states. A pending export becomes ready or failed, and a ready
+// one expires once its archive is deleted.
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired"
+)
+
+// DataExport is a user's request for a copy of everything held about them.
+// The archive is a zip of JSON files, one per kind of data.
+type DataExport struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uint       `json:"user_id" gorm:"not null"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size,omitempty"`
+	Error       string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+	// DownloadURL is a signed link to the archive, set while it is ready.
+	DownloadURL string `json:"download_url,omitempty" gorm:"-"`
+}
+
+// AccountHook lets another module act on a user's account, for example
+// when it is being deleted.
+type AccountHook func(ctx context.Context, userID uint) error
+
+// ExportFunc collects what a module holds about a user for a data export.
+// The result is written to the archive as JSON.
+type ExportFunc func(ctx context.Context, userID uint) (interface{}, error)
+
+type WishlistInput struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddWishlistItemInput struct {
+	ProductID uuid.UUID  `json:"product_id" validate:"required"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+}
+
+// WishlistCartInput adds a wishlist item to the cart. VariantID is needed
+// only for items saved without one.
+type WishlistCartInput struct {
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity  int        `json:"quantity" validate:"omitempty,min=1,max=100"`
+}
+
+type UpdateUserInput struct {
+	FirstName *string `json:"first_name,omitempty" validate:"omitempty,max=100"`
+	LastName  *string `json:"last_name,omitempty" validate:"omitempty,max=100"`
+}
+
+type UpdateCustomerProfileInput struct {
+	Phone *string `json:"phone,omitempty" validate:"omitempty,e164"`
+	// DateOfBirth is a calendar date, YYYY-MM-DD.
+	DateOfBirth       *string `json:"date_of_birth,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	PreferredLanguage *string `json:"preferred_language,omitempty" validate:"omitempty,bcp47_language_tag"`
+	MarketingOptIn    *bool   `json:"marketing_opt_in,omitempty"`
+	// Opting out also forgets the products viewed so far.
+	RecentlyViewedOptOut *bool `json:"recently_viewed_opt_out,omitempty"`
+}
+
+type UpdateSellerProfileInput struct {
+	DisplayName  *string `json:"display_name,omitempty" validate:"omitempty,min=2,max=100"`
+	Bio          *string `json:"bio,omitempty" validate:"omitempty,max=2000"`
+	Website      *string `json:"website,omitempty" validate:"omitempty,url,max=255"`
+	SupportEmail *string `json:"support_email,omitempty" validate:"omitempty,email,max=255"`
+	SupportPhone *string `json:"support_phone,omitempty" validate:"omitempty,e164"`
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type UserRepository interface {
+	GetByID(ctx context.Context, id uint) (*User, error)
+	// Update saves the fields a user may change about themselves.
+	Update(ctx context.Context, user *User) error
+	// UpdateDeletion saves when deletion was requested and when it is due.
+	UpdateDeletion(ctx context.Context, user *User) error
+	// ListDueForDeletion returns accounts whose grace period ended before t
+	// and that are not anonymized yet.
+	ListDueForDeletion(ctx context.Context, t time.Time, limit int) ([]*User, error)
+	// Anonymize replaces the user's personal fields with tombstones and
+	// deletes their profiles, addresses and wishlists. The users row is kept so that
+	// orders and financial records still point at an account.
+	Anonymize(ctx context.Context, id uint, at time.Time) error
+}
+
+type ProfileRepository interface {
+	GetCustomerProfile(ctx context.Context, userID uint) (*CustomerProfile, error)
+	SaveCustomerProfile(ctx context.Context, profile *CustomerProfile) error
+	GetSellerProfile(ctx context.Context, userID uint) (*SellerProfile, error)
+	SaveSellerProfile(ctx context.Context, profile *SellerProfile) error
+	GetSellerAccount(ctx context.Context, userID uint) (*SellerAccount, error)
+}
+
+type AddressRepository interface {
+	// Create and Update clear the user's other default shipping or billing
+	// address when the saved one takes over the flag.
+	Create(ctx context.Context, address *Address) error
+	Update(ctx context.Context, address *Address) error
+	GetByID(ctx context.Context, userID uint, id uuid.UUID) (*Address, error)
+	ListByUserID(ctx context.Context, userID uint) ([]*Address, error)
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+	// Delete removes the address and hands any default flag it held to the
+	// user's most recently updated remaining address.
+	Delete(ctx context.Context, userID uint, id uuid.UUID) error
+}
+
+type WishlistRepository interface {
+	Create(ctx context.Context, wishlist *Wishlist) error
+	Update(ctx context.Context, wishlist *Wishlist) error
+	Delete(ctx context.Context, userID uint, id uuid.UUID) error
+	// GetByID returns the user's wishlist with its items, oldest first.
+	GetByID(ctx context.Context, userID uint, id uuid.UUID) (*Wishlist, error)
+	GetByShareToken(ctx context.Context, token string) (*Wishlist, error)
+	// ListByUserID returns the user's wishlists with their item counts but
+	// without the items.
+	ListByUserID(ctx context.Context, userID uint) ([]*Wishlist, error)
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+
+	AddItem(ctx context.Context, item *WishlistItem) error
+	RemoveItem(ctx context.Context, wishlistID, id uuid.UUID) error
+	CountItems(ctx context.Context, wishlistID uuid.UUID) (int64, error)
+	HasItem(ctx context.Context, wishlistID, productID uuid.UUID, variantID *uuid.UUID) (bool, error)
+}
+
+type DataExportRepository interface {
+	Create(ctx context.Context, export *DataExport) error
+	Update(ctx context.Context, export *DataExport) error
+	GetByID(ctx context.Context, id uuid.UUID) (*DataExport, error)
+	ListByUserID(ctx context.Context, userID uint) ([]*DataExport, error)
+	// HasPending reports whether the user has an export created after since
+	// that is still being prepared.
+	HasPending(ctx context.Context, userID uint, since time.Time) (bool, error)
+	// ListExpired returns ready exports whose archive expired before t.
+	ListExpired(ctx context.Context, t time.Time) ([]*DataExport, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
@@ -1,11 +1,80 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"github.com/google/uuid"
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+)
 
 type UserService interface {
 	GetUserByID(ctx context.Context, id uint) (*User, error)
 	UpdateUser(ctx context.Context, id uint, input UpdateUserInput) (*User, error)
-	DeleteUser(ctx context.Context, id uint) error
+	// RequestDeletion schedules the account to be anonymized after a grace
+	// period and signs the user out everywhere. Logging in again still
+	// works until then, so the user can cancel.
+	RequestDeletion(ctx context.Context, id uint) (*User, error)
+	CancelDeletion(ctx context.Context, id uint) (*User, error)
+	// AnonymizeDueUsers anonymizes a batch of accounts whose grace period
+	// has ended and returns how many it anonymized.
+	AnonymizeDueUsers(ctx context.Context) (int, error)
+	// RegisterDeletionHook adds a hook run when a user requests deletion.
+	RegisterDeletionHook(fn AccountHook)
+	// RegisterAnonymizeHook adds a hook run just before an account is
+	// anonymized, for modules to scrub personal data they hold, such as the
+	// addresses copied into orders. An account whose hook fails is retried
+	// on the next run.
+	RegisterAnonymizeHook(fn AccountHook)
 	GetCustomerProfile(ctx context.Context, userID uint) (*CustomerProfile, error)
+	UpdateCustomerProfile(ctx context.Context, userID uint, input UpdateCustomerProfileInput) (*CustomerProfile, error)
 	GetSellerProfile(ctx context.Context, userID uint) (*SellerProfile, error)
-}
\ No newline at end of file
+	UpdateSellerProfile(ctx context.Context, userID uint, input UpdateSellerProfileInput) (*SellerProfile, error)
+
+	ListAddresses(ctx context.Context, userID uint) ([]*Address, error)
+	GetAddress(ctx context.Context, userID uint, id uuid.UUID) (*Address, error)
+	CreateAddress(ctx context.Context, userID uint, input AddressInput) (*Address, error)
+	UpdateAddress(ctx context.Context, userID uint, id uuid.UUID, input AddressInput) (*Address, error)
+	DeleteAddress(ctx context.Context, userID uint, id uuid.UUID) error
+	// SnapshotAddress copies one of the user's addresses for an order.
+	SnapshotAddress(ctx context.Context, userID uint, id uuid.UUID) (*AddressSnapshot, error)
+
+	ListWishlists(ctx context.Context, userID uint) ([]*Wishlist, error)
+	// GetWishlist returns the wishlist with its items priced from the
+	// current catalog.
+	GetWishlist(ctx context.Context, userID uint, id uuid.UUID) (*Wishlist, error)
+	CreateWishlist(ctx context.Context, userID uint, input WishlistInput) (*Wishlist, error)
+	RenameWishlist(ctx context.Context, userID uint, id uuid.UUID, input WishlistInput) (*Wishlist, error)
+	DeleteWishlist(ctx context.Context, userID uint, id uuid.UUID) error
+	AddWishlistItem(ctx context.Context, userID uint, wishlistID uuid.UUID, input AddWishlistItemInput) (*WishlistItem, error)
+	RemoveWishlistItem(ctx context.Context, userID uint, wishlistID, itemID uuid.UUID) error
+	// ShareWishlist gives the wishlist a share token, keeping the existing
+	// one if it is already shared.
+	ShareWishlist(ctx context.Context, userID uint, id uuid.UUID) (*Wishlist, error)
+	UnshareWishlist(ctx context.Context, userID uint, id uuid.UUID) error
+	GetSharedWishlist(ctx context.Context, token string) (*SharedWishlist, error)
+	AddWishlistItemToCart(ctx context.Context, userID uint, wishlistID, itemID uuid.UUID, input WishlistCartInput) error
+	// RegisterCart sets the cart that wishlist items are added to.
+	RegisterCart(cart Cart)
+
+	// RecordProductView adds the product to the viewer's recently viewed
+	// list, unless it is not active or a user opted out. A user who sends
+	// their guest session also takes over what they viewed as a guest.
+	RecordProductView(ctx context.Context, viewer Viewer, productID uuid.UUID) error
+	// ListRecentlyViewed returns up to limit active products the user viewed,
+	// most recent first.
+	ListRecentlyViewed(ctx context.Context, userID uint, limit int) ([]*productdomain.ProductWithVariants, error)
+	ClearRecentlyViewed(ctx context.Context, userID uint) error
+
+	// RequestDataExport starts preparing an archive of the user's data in
+	// the background and notifies them with a download link when it is ready.
+	RequestDataExport(ctx context.Context, userID uint) (*DataExport, error)
+	ListDataExports(ctx context.Context, userID uint) ([]*DataExport, error)
+	GetDataExport(ctx context.Context, userID uint, id uuid.UUID) (*DataExport, error)
+	// OpenDataExport checks a signed download link and returns the export
+	// and the path of its archive.
+	OpenDataExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*DataExport, string, error)
+	// RegisterExportSource adds a file with the given name to every data
+	// export. Modules holding user data call it while the app starts.
+	RegisterExportSource(name string, fn ExportFunc)
+	// WaitForExports blocks until the exports being prepared are finished.
+	WaitForExports()
+}
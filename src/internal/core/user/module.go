@@ -0,0 +1,80 @@
+package user
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"golang_marketplace/src/internal/core/user/delivery/http"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/internal/core/user/repository"
+	"golang_marketplace/src/internal/core/user/service"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type Module struct {
+	Service domain.UserService
+	logger  *slog.Logger
+}
+
+func NewModule(
+	db *gorm.DB,
+	catalog domain.Catalog,
+	views domain.RecentList,
+	notifier domain.Notifier,
+	exports service.ExportOptions,
+	deletion service.DeletionOptions,
+	recent service.RecentlyViewedOptions,
+	logger *slog.Logger,
+) *Module {
+	logger = logger.With("module", "user")
+
+	userRepo := repository.NewUserRepository(db)
+	profileRepo := repository.NewProfileRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	exportRepo := repository.NewDataExportRepository(db)
+	wishlistRepo := repository.NewWishlistRepository(db)
+
+	userService := service.NewUserService(userRepo, profileRepo, addressRepo, exportRepo, wishlistRepo, catalog, views, notifier, exports, deletion, recent, logger)
+
+	return &Module{
+		Service: userService,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes mounts the /users/me routes behind authenticate, and the
+// data export downloads and shared wishlists, which are reached through
+// links carrying their own secret.
+func (m *Module) RegisterRoutes(router *gin.RouterGroup, authenticate gin.HandlerFunc) {
+	http.RegisterRoutes(router, m.Service, authenticate, m.logger)
+}
+
+// TrackProductViews returns middleware for the product page that records
+// views in the recently viewed lists. It expects optional authentication in
+// front of it.
+func (m *Module) TrackProductViews() gin.HandlerFunc {
+	return http.TrackProductViews(m.Service, m.logger)
+}
+
+// RunDeletionSweeper anonymizes accounts whose deletion grace period has
+// ended, every interval until ctx is done.
+func (m *Module) RunDeletionSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := m.Service.AnonymizeDueUsers(ctx)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "failed to anonymize deleted accounts", "error", err)
+		} else if n > 0 {
+			m.logger.InfoContext(ctx, "anonymized deleted accounts", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
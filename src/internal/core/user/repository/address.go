@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) domain.AddressRepository {
+	return &addressRepository{db: db}
+}
+
+func (r *addressRepository) Create(ctx context.Context, address *domain.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearOtherDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Create(address).Error
+	})
+}
+
+func (r *addressRepository) Update(ctx context.Context, address *domain.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearOtherDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Save(address).Error
+	})
+}
+
+func (r *addressRepository) GetByID(ctx context.Context, userID uint, id uuid.UUID) (*domain.Address, error) {
+	var address domain.Address
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&address, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *addressRepository) ListByUserID(ctx context.Context, userID uint) ([]*domain.Address, error) {
+	var addresses []*domain.Address
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Where("user_id = ?", userID).
+		Order("is_default_shipping DESC, is_default_billing DESC, updated_at DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *addressRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Model(&domain.Address{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *addressRepository) Delete(ctx context.Context, userID uint, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var address domain.Address
+		if err := tx.First(&address, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+
+		for column, held := range map[string]bool{
+			"is_default_shipping": address.IsDefaultShipping,
+			"is_default_billing":  address.IsDefaultBilling,
+		} {
+			if !held {
+				continue
+			}
+			next := tx.Model(&domain.Address{}).
+				Select("id").
+				Where("user_id = ?", userID).
+				Order("updated_at DESC").
+				Limit(1)
+			if err := tx.Model(&domain.Address{}).
+				Where("id = (?)", next).
+				UpdateColumn(column, true).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// clearOtherDefaults takes the default flags the address is about to hold
+// away from the user's other addresses.
+func clearOtherDefaults(tx *gorm.DB, address *domain.Address) error {
+	if address.IsDefaultShipping {
+		if err := tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_shipping", address.UserID, address.ID).
+			UpdateColumn("is_default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+	if address.IsDefaultBilling {
+		if err := tx.Model(&domain.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_billing", address.UserID, address.ID).
+			UpdateColumn("is_default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
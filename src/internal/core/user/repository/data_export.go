@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) domain.DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) Create(ctx context.Context, export *domain.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+func (r *dataExportRepository) Update(ctx context.Context, export *domain.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+func (r *dataExportRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&export, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) ListByUserID(ctx context.Context, userID uint) ([]*domain.DataExport, error) {
+	var exports []*domain.DataExport
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) HasPending(ctx context.Context, userID uint, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Model(&domain.DataExport{}).
+		Where("user_id = ? AND status = ? AND created_at > ?", userID, domain.ExportStatusPending, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *dataExportRepository) ListExpired(ctx context.Context, t time.Time) ([]*domain.DataExport, error) {
+	var exports []*domain.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", domain.ExportStatusReady, t).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.DataExport{}).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+	"time"
+)
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) domain.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	var user domain.User
+	// read back right after updates, so skip replicas
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Model(user).
+		Select("first_name", "last_name", "updated_at").
+		Updates(user).Error
+}
+
+func (r *userRepository) UpdateDeletion(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Model(user).
+		Select("deletion_requested_at", "deletion_scheduled_for", "updated_at").
+		Updates(user).Error
+}
+
+func (r *userRepository) ListDueForDeletion(ctx context.Context, t time.Time, limit int) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.db.WithContext(ctx).
+		Where("deletion_scheduled_for <= ? AND anonymized_at IS NULL", t).
+		Order("deletion_scheduled_for").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// "!" is never a valid bcrypt hash, so no password matches it
+		result := tx.Model(&domain.User{}).
+			Where("id = ? AND anonymized_at IS NULL", id).
+			Updates(map[string]interface{}{
+				"email":             fmt.Sprintf("deleted-user-%d@deleted.invalid", id),
+				"first_name":        "",
+				"last_name":         "",
+				"password_hash":     "!",
+				"email_verified_at": nil,
+				"anonymized_at":     at,
+				"updated_at":        at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		for _, model := range []interface{}{&domain.CustomerProfile{}, &domain.SellerProfile{}, &domain.Address{}, &domain.Wishlist{}} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
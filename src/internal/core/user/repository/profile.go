@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type profileRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) domain.ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+func (r *profileRepository) GetCustomerProfile(ctx context.Context, userID uint) (*domain.CustomerProfile, error) {
+	var profile domain.CustomerProfile
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&profile, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *profileRepository) SaveCustomerProfile(ctx context.Context, profile *domain.CustomerProfile) error {
+	return r.db.WithContext(ctx).Save(profile).Error
+}
+
+func (r *profileRepository) GetSellerProfile(ctx context.Context, userID uint) (*domain.SellerProfile, error) {
+	var profile domain.SellerProfile
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		First(&profile, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *profileRepository) SaveSellerProfile(ctx context.Context, profile *domain.SellerProfile) error {
+	return r.db.WithContext(ctx).Omit("Seller").Save(profile).Error
+}
+
+func (r *profileRepository) GetSellerAccount(ctx context.Context, userID uint) (*domain.SellerAccount, error) {
+	var seller domain.SellerAccount
+	err := r.db.WithContext(ctx).First(&seller, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &seller, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) domain.WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+func (r *wishlistRepository) Create(ctx context.Context, wishlist *domain.Wishlist) error {
+	return r.db.WithContext(ctx).Omit("Items").Create(wishlist).Error
+}
+
+func (r *wishlistRepository) Update(ctx context.Context, wishlist *domain.Wishlist) error {
+	return r.db.WithContext(ctx).Model(wishlist).
+		Select("name", "share_token", "updated_at").
+		Updates(wishlist).Error
+}
+
+func (r *wishlistRepository) Delete(ctx context.Context, userID uint, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.Wishlist{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *wishlistRepository) GetByID(ctx context.Context, userID uint, id uuid.UUID) (*domain.Wishlist, error) {
+	return r.get(ctx, "id = ? AND user_id = ?", id, userID)
+}
+
+func (r *wishlistRepository) GetByShareToken(ctx context.Context, token string) (*domain.Wishlist, error) {
+	return r.get(ctx, "share_token = ?", token)
+}
+
+func (r *wishlistRepository) get(ctx context.Context, query string, args ...interface{}) (*domain.Wishlist, error) {
+	var wishlist domain.Wishlist
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Where(query, args...).
+		First(&wishlist).Error
+	if err != nil {
+		return nil, err
+	}
+	wishlist.ItemCount = int64(len(wishlist.Items))
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) ListByUserID(ctx context.Context, userID uint) ([]*domain.Wishlist, error) {
+	var wishlists []*domain.Wishlist
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Select("wishlists.*, (SELECT COUNT(*) FROM wishlist_items WHERE wishlist_items.wishlist_id = wishlists.id) AS item_count").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Model(&domain.Wishlist{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *wishlistRepository) AddItem(ctx context.Context, item *domain.WishlistItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *wishlistRepository) RemoveItem(ctx context.Context, wishlistID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&domain.WishlistItem{}, "id = ? AND wishlist_id = ?", id, wishlistID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *wishlistRepository) CountItems(ctx context.Context, wishlistID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Model(&domain.WishlistItem{}).
+		Where("wishlist_id = ?", wishlistID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *wishlistRepository) HasItem(ctx context.Context, wishlistID, productID uuid.UUID, variantID *uuid.UUID) (bool, error) {
+	query := r.db.WithContext(ctx).
+		Clauses(dbresolver.Write).
+		Model(&domain.WishlistItem{}).
+		Where("wishlist_id = ? AND product_id = ?", wishlistID, productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"strings"
+)
+
+func (s *userService) ListAddresses(ctx context.Context, userID uint) ([]*domain.Address, error) {
+	addresses, err := s.addressRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+func (s *userService) GetAddress(ctx context.Context, userID uint, id uuid.UUID) (*domain.Address, error) {
+	address, err := s.addressRepo.GetByID(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAddressNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	return address, nil
+}
+
+// CreateAddress adds an address to the user's book. The first address
+// becomes both the default shipping and the default billing address.
+func (s *userService) CreateAddress(ctx context.Context, userID uint, input domain.AddressInput) (*domain.Address, error) {
+	input = normalizeAddress(input)
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	count, err := s.addressRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count addresses: %w", err)
+	}
+	if count >= domain.MaxAddresses {
+		return nil, domain.ErrTooManyAddresses
+	}
+
+	address := &domain.Address{UserID: userID}
+	applyAddress(address, input)
+	if count == 0 {
+		address.IsDefaultShipping = true
+		address.IsDefaultBilling = true
+	}
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		return nil, fmt.Errorf("failed to create address: %w", err)
+	}
+	return address, nil
+}
+
+// UpdateAddress replaces the address. Clearing a default flag leaves the
+// user without that default until another address takes it.
+func (s *userService) UpdateAddress(ctx context.Context, userID uint, id uuid.UUID, input domain.AddressInput) (*domain.Address, error) {
+	input = normalizeAddress(input)
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	address, err := s.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyAddress(address, input)
+
+	if err := s.addressRepo.Update(ctx, address); err != nil {
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+	return address, nil
+}
+
+func (s *userService) DeleteAddress(ctx context.Context, userID uint, id uuid.UUID) error {
+	err := s.addressRepo.Delete(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrAddressNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) SnapshotAddress(ctx context.Context, userID uint, id uuid.UUID) (*domain.AddressSnapshot, error) {
+	address, err := s.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return address.Snapshot(), nil
+}
+
+func normalizeAddress(input domain.AddressInput) domain.AddressInput {
+	input.Label = strings.TrimSpace(input.Label)
+	input.FullName = strings.TrimSpace(input.FullName)
+	input.Country = strings.ToUpper(strings.TrimSpace(input.Country))
+	input.City = strings.TrimSpace(input.City)
+	input.District = strings.TrimSpace(input.District)
+	input.PostalCode = strings.ToUpper(strings.TrimSpace(input.PostalCode))
+	input.Line1 = strings.TrimSpace(input.Line1)
+	input.Line2 = strings.TrimSpace(input.Line2)
+	return input
+}
+
+func applyAddress(address *domain.Address, input domain.AddressInput) {
+	address.Label = input.Label
+	address.FullName = input.FullName
+	address.Phone = input.Phone
+	address.Country = input.Country
+	address.City = input.City
+	address.District = input.District
+	address.PostalCode = input.PostalCode
+	address.Line1 = input.Line1
+	address.Line2 = input.Line2
+	address.IsDefaultShipping = input.IsDefaultShipping
+	address.IsDefaultBilling = input.IsDefaultBilling
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"os"
+	"time"
+)
+
+// DeletionOptions configures account deletion.
+type DeletionOptions struct {
+	// GracePeriod is how long a user has to change their mind before their
+	// account is anonymized.
+	GracePeriod time.Duration
+}
+
+// anonymizeBatchSize caps how many accounts one AnonymizeDueUsers run
+// handles.
+const anonymizeBatchSize = 100
+
+func (s *userService) RegisterDeletionHook(fn domain.AccountHook) {
+	s.deletionHooks = append(s.deletionHooks, fn)
+}
+
+func (s *userService) RegisterAnonymizeHook(fn domain.AccountHook) {
+	s.anonymizeHooks = append(s.anonymizeHooks, fn)
+}
+
+// RequestDeletion is idempotent: asking again keeps the original schedule
+// but runs the hooks again, in case they failed the first time.
+func (s *userService) RequestDeletion(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledFor == nil {
+		now := time.Now()
+		scheduledFor := now.Add(s.deletion.GracePeriod)
+		user.DeletionRequestedAt = &now
+		user.DeletionScheduledFor = &scheduledFor
+		if err := s.userRepo.UpdateDeletion(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to schedule deletion: %w", err)
+		}
+		s.logger.InfoContext(ctx, "account deletion requested", "user_id", id, "scheduled_for", scheduledFor)
+	}
+
+	for _, hook := range s.deletionHooks {
+		if err := hook(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to run deletion hook: %w", err)
+		}
+	}
+	return user, nil
+}
+
+func (s *userService) CancelDeletion(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledFor == nil {
+		return nil, domain.ErrNoPendingDeletion
+	}
+
+	user.DeletionRequestedAt = nil
+	user.DeletionScheduledFor = nil
+	if err := s.userRepo.UpdateDeletion(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "account deletion cancelled", "user_id", id)
+	return user, nil
+}
+
+func (s *userService) AnonymizeDueUsers(ctx context.Context) (int, error) {
+	users, err := s.userRepo.ListDueForDeletion(ctx, time.Now(), anonymizeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+	}
+
+	anonymized := 0
+	for _, user := range users {
+		if err := s.anonymize(ctx, user.ID); err != nil {
+			s.logger.ErrorContext(ctx, "failed to anonymize account", "user_id", user.ID, "error", err)
+			continue
+		}
+		anonymized++
+	}
+	return anonymized, nil
+}
+
+// anonymize scrubs one account. The hooks run first, so that a failure
+// leaves the account due and it is retried as a whole.
+func (s *userService) anonymize(ctx context.Context, userID uint) error {
+	for _, hook := range s.anonymizeHooks {
+		if err := hook(ctx, userID); err != nil {
+			return fmt.Errorf("failed to run anonymize hook: %w", err)
+		}
+	}
+
+	if err := s.ClearRecentlyViewed(ctx, userID); err != nil {
+		return err
+	}
+
+	exports, err := s.exportRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list data exports: %w", err)
+	}
+	for _, export := range exports {
+		if err := os.Remove(s.exportPath(export.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete data export: %w", err)
+		}
+	}
+	if err := s.exportRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete data exports: %w", err)
+	}
+
+	err = s.userRepo.Anonymize(ctx, userID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "account anonymized", "user_id", userID)
+	return nil
+}
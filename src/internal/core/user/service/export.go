@@ -0,0 +1,360 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	notificationdomain "golang_marketplace/src/internal/core/notification/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportOptions configures personal data exports.
+type ExportOptions struct {
+	// Dir holds the archives until they expire.
+	Dir string
+	// TTL is how long an archive, and the link to it, stays available.
+	TTL        time.Duration
+	SigningKey []byte
+	// BaseURL is the API root that download links point to.
+	BaseURL string
+}
+
+// exportTimeout bounds how long an archive may take to build. A pending
+// export older than that was lost, for example to a restart, and no longer
+// blocks a new request.
+const exportTimeout = 10 * time.Minute
+
+const exportReadyMessage = "The copy of your data you asked for is ready to download. The link expires on %s. If you did not ask for this, please change your password."
+
+// plannedExports are files of data the marketplace does not store yet.
+// They are written empty so that an archive states there is nothing to
+// export rather than leaving the reader to wonder; a module that starts
+// storing one registers a source under the same name, which replaces it.
+var plannedExports = []string{"orders", "reviews"}
+
+type exportSource struct {
+	name string
+	fn   domain.ExportFunc
+}
+
+func (s *userService) RegisterExportSource(name string, fn domain.ExportFunc) {
+	s.exportSources = append(s.exportSources, exportSource{name: name, fn: fn})
+}
+
+func (s *userService) hasExportSource(name string) bool {
+	for _, source := range s.exportSources {
+		if source.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *userService) RequestDataExport(ctx context.Context, userID uint) (*domain.DataExport, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.exportRepo.HasPending(ctx, userID, time.Now().Add(-exportTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check data exports: %w", err)
+	}
+	if pending {
+		return nil, domain.ErrExportInProgress
+	}
+
+	export := &domain.DataExport{UserID: userID, Status: domain.ExportStatusPending}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	s.exportsRunning.Add(1)
+	go func(export domain.DataExport) {
+		defer s.exportsRunning.Done()
+		s.runExport(user, export)
+	}(*export)
+	return export, nil
+}
+
+func (s *userService) WaitForExports() {
+	s.exportsRunning.Wait()
+}
+
+func (s *userService) ListDataExports(ctx context.Context, userID uint) ([]*domain.DataExport, error) {
+	exports, err := s.exportRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	for _, export := range exports {
+		s.setDownloadURL(export)
+	}
+	return exports, nil
+}
+
+func (s *userService) GetDataExport(ctx context.Context, userID uint, id uuid.UUID) (*domain.DataExport, error) {
+	export, err := s.exportRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && export.UserID != userID) {
+		return nil, domain.ErrExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	s.setDownloadURL(export)
+	return export, nil
+}
+
+func (s *userService) OpenDataExport(ctx context.Context, id uuid.UUID, expires int64, signature string) (*domain.DataExport, string, error) {
+	if time.Now().Unix() > expires || !hmac.Equal([]byte(signature), []byte(s.signExport(id, expires))) {
+		return nil, "", domain.ErrInvalidExportURL
+	}
+
+	export, err := s.exportRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", domain.ErrInvalidExportURL
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get data export: %w", err)
+	}
+	if export.Status != domain.ExportStatusReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, "", domain.ErrInvalidExportURL
+	}
+
+	return export, s.exportPath(export.ID), nil
+}
+
+// runExport builds the archive outside of the request that asked for it.
+func (s *userService) runExport(user *domain.User, export domain.DataExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	size, err := s.writeExport(ctx, export.ID, user)
+	now := time.Now()
+	export.CompletedAt = &now
+	if err != nil {
+		s.logger.ErrorContext(ctx, "data export failed", "export_id", export.ID, "user_id", user.ID, "error", err)
+		export.Status = domain.ExportStatusFailed
+		export.Error = err.Error()
+	} else {
+		expiresAt := now.Add(s.exports.TTL)
+		export.Status = domain.ExportStatusReady
+		export.Size = size
+		export.ExpiresAt = &expiresAt
+	}
+
+	if err := s.exportRepo.Update(ctx, &export); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save data export", "export_id", export.ID, "error", err)
+		return
+	}
+
+	if export.Status == domain.ExportStatusReady {
+		s.setDownloadURL(&export)
+		err := s.notifier.Notify(ctx, &notificationdomain.Notification{
+			UserID: user.ID,
+			Type:   notificationdomain.EventDataExportReady,
+			Title:  "Your data export is ready",
+			Body:   fmt.Sprintf(exportReadyMessage, export.ExpiresAt.UTC().Format(time.RFC1123)),
+			Link:   export.DownloadURL,
+			Data:   notificationdomain.Data{"export_id": export.ID.String()},
+		})
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to notify about data export", "export_id", export.ID, "error", err)
+		}
+	}
+
+	s.purgeExpiredExports(ctx)
+}
+
+// writeExport writes the user's data to a new archive and returns its size.
+// The archive only appears under its final name once complete.
+func (s *userService) writeExport(ctx context.Context, id uuid.UUID, user *domain.User) (int64, error) {
+	if err := os.MkdirAll(s.exports.Dir, 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.exports.Dir, "export-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	add := func(name string, data interface{}) error {
+		w, err := archive.Create(name + ".json")
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	profile, err := s.exportProfile(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+	if err := add("profile", profile); err != nil {
+		return 0, err
+	}
+
+	addresses, err := s.ListAddresses(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := add("addresses", addresses); err != nil {
+		return 0, err
+	}
+
+	wishlists, err := s.exportWishlists(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := add("wishlists", wishlists); err != nil {
+		return 0, err
+	}
+
+	var viewed []string
+	if s.views != nil {
+		viewed, err = s.views.Recent(ctx, userViewsKey(user.ID), s.recent.Limit)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get recently viewed products: %w", err)
+		}
+	}
+	if viewed == nil {
+		viewed = []string{}
+	}
+	if err := add("recently_viewed", viewed); err != nil {
+		return 0, err
+	}
+
+	for _, name := range plannedExports {
+		if s.hasExportSource(name) {
+			continue
+		}
+		if err := add(name, []struct{}{}); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, source := range s.exportSources {
+		data, err := source.fn(ctx, user.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to collect %s: %w", source.name, err)
+		}
+		if err := add(source.name, data); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.exportPath(id)); err != nil {
+		return 0, fmt.Errorf("failed to store archive: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// exportedProfile is the profile.json file of an archive.
+type exportedProfile struct {
+	Account         *domain.User            `json:"account"`
+	CustomerProfile *domain.CustomerProfile `json:"customer_profile"`
+	SellerProfile   *domain.SellerProfile   `json:"seller_profile,omitempty"`
+}
+
+func (s *userService) exportProfile(ctx context.Context, user *domain.User) (*exportedProfile, error) {
+	customer, err := s.GetCustomerProfile(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	seller, err := s.GetSellerProfile(ctx, user.ID)
+	if errors.Is(err, domain.ErrNotSeller) {
+		seller = nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &exportedProfile{Account: user, CustomerProfile: customer, SellerProfile: seller}, nil
+}
+
+// exportWishlists returns the user's wishlists with their items as saved,
+// without current catalog data.
+func (s *userService) exportWishlists(ctx context.Context, userID uint) ([]*domain.Wishlist, error) {
+	wishlists, err := s.ListWishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i, wishlist := range wishlists {
+		if wishlists[i], err = s.getWishlist(ctx, userID, wishlist.ID); err != nil {
+			return nil, err
+		}
+	}
+	return wishlists, nil
+}
+
+// purgeExpiredExports deletes the archives of every user whose links have
+// expired.
+func (s *userService) purgeExpiredExports(ctx context.Context) {
+	exports, err := s.exportRepo.ListExpired(ctx, time.Now())
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to list expired data exports", "error", err)
+		return
+	}
+
+	for _, export := range exports {
+		if err := os.Remove(s.exportPath(export.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.WarnContext(ctx, "failed to delete data export", "export_id", export.ID, "error", err)
+			continue
+		}
+		export.Status = domain.ExportStatusExpired
+		if err := s.exportRepo.Update(ctx, export); err != nil {
+			s.logger.WarnContext(ctx, "failed to save data export", "export_id", export.ID, "error", err)
+		}
+	}
+}
+
+func (s *userService) setDownloadURL(export *domain.DataExport) {
+	if export.Status != domain.ExportStatusReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return
+	}
+
+	expires := export.ExpiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signExport(export.ID, expires))
+	export.DownloadURL = strings.TrimRight(s.exports.BaseURL, "/") + "/exports/" + export.ID.String() + "/download?" + query.Encode()
+}
+
+// signExport authenticates a download link, so that it works without
+// logging in until it expires.
+func (s *userService) signExport(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.exports.SigningKey)
+	fmt.Fprintf(mac, "%s:%d", id, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *userService) exportPath(id uuid.UUID) string {
+	return filepath.Join(s.exports.Dir, id.String()+".zip")
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"github.com/google/uuid"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type stubProfiles struct {
+	domain.ProfileRepository
+}
+
+func (stubProfiles) GetCustomerProfile(context.Context, uint) (*domain.CustomerProfile, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (stubProfiles) GetSellerAccount(context.Context, uint) (*domain.SellerAccount, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+type stubUsers struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r stubUsers) GetByID(context.Context, uint) (*domain.User, error) {
+	return r.user, nil
+}
+
+type stubAddresses struct {
+	domain.AddressRepository
+}
+
+func (stubAddresses) ListByUserID(context.Context, uint) ([]*domain.Address, error) {
+	return []*domain.Address{}, nil
+}
+
+type stubWishlists struct {
+	domain.WishlistRepository
+}
+
+func (stubWishlists) ListByUserID(context.Context, uint) ([]*domain.Wishlist, error) {
+	return []*domain.Wishlist{}, nil
+}
+
+func TestWriteExportFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string]domain.ExportFunc
+		want    map[string]string
+	}{
+		{
+			name: "data that is not stored yet is exported empty",
+			want: map[string]string{"orders.json": "[]", "reviews.json": "[]", "recently_viewed.json": "[]"},
+		},
+		{
+			name: "a registered source replaces the empty file",
+			sources: map[string]domain.ExportFunc{
+				"orders": func(context.Context, uint) (interface{}, error) {
+					return []string{"order-1"}, nil
+				},
+			},
+			want: map[string]string{"orders.json": `["order-1"]`, "reviews.json": "[]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{ID: 1, Email: "jane@example.com"}
+			s := &userService{
+				userRepo:     stubUsers{user: user},
+				profileRepo:  stubProfiles{},
+				addressRepo:  stubAddresses{},
+				wishlistRepo: stubWishlists{},
+				exports:      ExportOptions{Dir: t.TempDir()},
+				logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			for name, fn := range tt.sources {
+				s.RegisterExportSource(name, fn)
+			}
+
+			id := uuid.New()
+			if _, err := s.writeExport(context.Background(), id, user); err != nil {
+				t.Fatal(err)
+			}
+
+			files := readArchive(t, s.exportPath(id))
+			for name, want := range tt.want {
+				got, ok := files[name]
+				if !ok {
+					t.Errorf("archive has no %s", name)
+					continue
+				}
+				if compact := strings.Join(strings.Fields(got), ""); compact != want {
+					t.Errorf("%s = %s, want %s", name, compact, want)
+				}
+			}
+		})
+	}
+}
+
+// readArchive returns the contents of every file in a zip archive and fails
+// the test if a name appears twice.
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		if _, ok := files[file.Name]; ok {
+			t.Errorf("archive has %s twice", file.Name)
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file.Name] = string(data)
+	}
+	return files
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// RecentlyViewedOptions configures the recently viewed lists. They are kept
+// in a RecentList; without one nothing is recorded and the lists are empty.
+type RecentlyViewedOptions struct {
+	// Limit is how many products a list keeps.
+	Limit int
+	// TTL is how long a list is kept after the last view.
+	TTL time.Duration
+}
+
+func (s *userService) RecordProductView(ctx context.Context, viewer domain.Viewer, productID uuid.UUID) error {
+	if s.views == nil {
+		return nil
+	}
+
+	product, err := s.getProduct(ctx, productID)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if product.Status != "active" {
+		return nil
+	}
+
+	key := guestViewsKey(viewer.GuestSession)
+	if viewer.UserID != 0 {
+		optedOut, err := s.viewsOptedOut(ctx, viewer.UserID)
+		if err != nil {
+			return err
+		}
+		if optedOut {
+			return nil
+		}
+		key = userViewsKey(viewer.UserID)
+
+		if viewer.GuestSession != "" {
+			if err := s.adoptGuestViews(ctx, viewer.GuestSession, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.views.PushRecent(ctx, key, productID.String(), s.recent.Limit, s.recent.TTL); err != nil {
+		return fmt.Errorf("failed to record product view: %w", err)
+	}
+	return nil
+}
+
+// ListRecentlyViewed drops products that no longer exist from the list as
+// it goes; inactive ones are skipped but kept, in case they come back.
+func (s *userService) ListRecentlyViewed(ctx context.Context, userID uint, limit int) ([]*productdomain.ProductWithVariants, error) {
+	if s.views == nil {
+		return []*productdomain.ProductWithVariants{}, nil
+	}
+
+	key := userViewsKey(userID)
+	ids, err := s.views.Recent(ctx, key, s.recent.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recently viewed products: %w", err)
+	}
+
+	products := make([]*productdomain.ProductWithVariants, 0, limit)
+	var gone []string
+	for _, member := range ids {
+		if len(products) == limit {
+			break
+		}
+		id, err := uuid.Parse(member)
+		if err != nil {
+			gone = append(gone, member)
+			continue
+		}
+		product, err := s.getProduct(ctx, id)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			gone = append(gone, member)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if product.Status == "active" {
+			products = append(products, product)
+		}
+	}
+
+	if len(gone) > 0 {
+		if err := s.views.RemoveRecent(ctx, key, gone...); err != nil {
+			s.logger.WarnContext(ctx, "failed to forget deleted products", "user_id", userID, "error", err)
+		}
+	}
+	return products, nil
+}
+
+func (s *userService) ClearRecentlyViewed(ctx context.Context, userID uint) error {
+	if s.views == nil {
+		return nil
+	}
+
+	if err := s.views.Delete(ctx, userViewsKey(userID)); err != nil {
+		return fmt.Errorf("failed to clear recently viewed products: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) viewsOptedOut(ctx context.Context, userID uint) (bool, error) {
+	profile, err := s.profileRepo.GetCustomerProfile(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get customer profile: %w", err)
+	}
+	return profile.RecentlyViewedOptOut, nil
+}
+
+// adoptGuestViews moves what was viewed before logging in to the user's
+// list, keeping the order, and forgets the guest's list.
+func (s *userService) adoptGuestViews(ctx context.Context, session, userKey string) error {
+	guestKey := guestViewsKey(session)
+	ids, err := s.views.Recent(ctx, guestKey, s.recent.Limit)
+	if err != nil {
+		return fmt.Errorf("failed to get guest views: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := s.views.PushRecent(ctx, userKey, ids[i], s.recent.Limit, s.recent.TTL); err != nil {
+			return fmt.Errorf("failed to record product view: %w", err)
+		}
+	}
+	if err := s.views.Delete(ctx, guestKey); err != nil {
+		return fmt.Errorf("failed to clear guest views: %w", err)
+	}
+	return nil
+}
+
+func userViewsKey(userID uint) string {
+	return "user:recently_viewed:user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func guestViewsKey(session string) string {
+	return "user:recently_viewed:guest:" + session
+}
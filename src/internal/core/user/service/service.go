@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+type userService struct {
+	userRepo     domain.UserRepository
+	profileRepo  domain.ProfileRepository
+	addressRepo  domain.AddressRepository
+	exportRepo   domain.DataExportRepository
+	wishlistRepo domain.WishlistRepository
+	catalog      domain.Catalog
+	cart         domain.Cart
+	views        domain.RecentList
+	notifier     domain.Notifier
+	exports      ExportOptions
+	deletion     DeletionOptions
+	recent       RecentlyViewedOptions
+	logger       *slog.Logger
+
+	exportSources  []exportSource
+	exportsRunning sync.WaitGroup
+	deletionHooks  []domain.AccountHook
+	anonymizeHooks []domain.AccountHook
+}
+
+func NewUserService(
+	userRepo domain.UserRepository,
+	profileRepo domain.ProfileRepository,
+	addressRepo domain.AddressRepository,
+	exportRepo domain.DataExportRepository,
+	wishlistRepo domain.WishlistRepository,
+	catalog domain.Catalog,
+	views domain.RecentList,
+	notifier domain.Notifier,
+	exports ExportOptions,
+	deletion DeletionOptions,
+	recent RecentlyViewedOptions,
+	logger *slog.Logger,
+) domain.UserService {
+	return &userService{
+		userRepo:     userRepo,
+		profileRepo:  profileRepo,
+		addressRepo:  addressRepo,
+		exportRepo:   exportRepo,
+		wishlistRepo: wishlistRepo,
+		catalog:      catalog,
+		views:        views,
+		notifier:     notifier,
+		exports:      exports,
+		deletion:     deletion,
+		recent:       recent,
+		logger:       logger,
+	}
+}
+
+// GetUserByID treats anonymized accounts as gone.
+func (s *userService) GetUserByID(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && user.AnonymizedAt != nil) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id uint, input domain.UpdateUserInput) (*domain.User, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*input.FirstName)
+	}
+	if input.LastName != nil {
+		user.LastName = strings.TrimSpace(*input.LastName)
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return user, nil
+}
+
+// GetCustomerProfile returns the user's customer profile, or an empty one
+// if they never filled it in.
+func (s *userService) GetCustomerProfile(ctx context.Context, userID uint) (*domain.CustomerProfile, error) {
+	if _, err := s.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profileRepo.GetCustomerProfile(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &domain.CustomerProfile{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer profile: %w", err)
+	}
+	return profile, nil
+}
+
+func (s *userService) UpdateCustomerProfile(ctx context.Context, userID uint, input domain.UpdateCustomerProfileInput) (*domain.CustomerProfile, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetCustomerProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Phone != nil {
+		profile.Phone = *input.Phone
+	}
+	if input.DateOfBirth != nil {
+		if *input.DateOfBirth == "" {
+			profile.DateOfBirth = nil
+		} else {
+			// already checked by validation
+			dateOfBirth, _ := time.Parse(time.DateOnly, *input.DateOfBirth)
+			if dateOfBirth.After(time.Now()) {
+				return nil, fmt.Errorf("%w: date_of_birth must be in the past", validator.ErrValidation)
+			}
+			profile.DateOfBirth = &dateOfBirth
+		}
+	}
+	if input.PreferredLanguage != nil {
+		profile.PreferredLanguage = *input.PreferredLanguage
+	}
+	if input.MarketingOptIn != nil {
+		profile.MarketingOptIn = *input.MarketingOptIn
+	}
+	if input.RecentlyViewedOptOut != nil {
+		profile.RecentlyViewedOptOut = *input.RecentlyViewedOptOut
+		if profile.RecentlyViewedOptOut {
+			if err := s.ClearRecentlyViewed(ctx, userID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.profileRepo.SaveCustomerProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save customer profile: %w", err)
+	}
+	return profile, nil
+}
+
+// GetSellerProfile returns the profile of the seller linked to the user.
+// Until it is filled in, it shows the seller's company name.
+func (s *userService) GetSellerProfile(ctx context.Context, userID uint) (*domain.SellerProfile, error) {
+	seller, err := s.profileRepo.GetSellerAccount(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotSeller
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller: %w", err)
+	}
+
+	profile, err := s.profileRepo.GetSellerProfile(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &domain.SellerProfile{
+			UserID:       userID,
+			DisplayName:  seller.CompanyName,
+			SupportEmail: seller.Email,
+			SupportPhone: seller.Phone,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get seller profile: %w", err)
+	}
+
+	profile.Seller = seller
+	return profile, nil
+}
+
+func (s *userService) UpdateSellerProfile(ctx context.Context, userID uint, input domain.UpdateSellerProfileInput) (*domain.SellerProfile, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetSellerProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.DisplayName != nil {
+		profile.DisplayName = strings.TrimSpace(*input.DisplayName)
+	}
+	if input.Bio != nil {
+		profile.Bio = strings.TrimSpace(*input.Bio)
+	}
+	if input.Website != nil {
+		profile.Website = *input.Website
+	}
+	if input.SupportEmail != nil {
+		profile.SupportEmail = *input.SupportEmail
+	}
+	if input.SupportPhone != nil {
+		profile.SupportPhone = *input.SupportPhone
+	}
+
+	if err := s.profileRepo.SaveSellerProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save seller profile: %w", err)
+	}
+	return profile, nil
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"golang_marketplace/src/pkg/validator"
+	"gorm.io/gorm"
+	"strings"
+)
+
+func (s *userService) RegisterCart(cart domain.Cart) {
+	s.cart = cart
+}
+
+func (s *userService) ListWishlists(ctx context.Context, userID uint) ([]*domain.Wishlist, error) {
+	wishlists, err := s.wishlistRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wishlists: %w", err)
+	}
+	return wishlists, nil
+}
+
+func (s *userService) GetWishlist(ctx context.Context, userID uint, id uuid.UUID) (*domain.Wishlist, error) {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.priceWishlistItems(ctx, wishlist.Items); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (s *userService) CreateWishlist(ctx context.Context, userID uint, input domain.WishlistInput) (*domain.Wishlist, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	count, err := s.wishlistRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count wishlists: %w", err)
+	}
+	if count >= domain.MaxWishlists {
+		return nil, domain.ErrTooManyWishlists
+	}
+
+	wishlist := &domain.Wishlist{UserID: userID, Name: input.Name}
+	if err := s.wishlistRepo.Create(ctx, wishlist); err != nil {
+		return nil, fmt.Errorf("failed to create wishlist: %w", err)
+	}
+	return wishlist, nil
+}
+
+func (s *userService) RenameWishlist(ctx context.Context, userID uint, id uuid.UUID, input domain.WishlistInput) (*domain.Wishlist, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist.Name = input.Name
+	if err := s.wishlistRepo.Update(ctx, wishlist); err != nil {
+		return nil, fmt.Errorf("failed to update wishlist: %w", err)
+	}
+	if err := s.priceWishlistItems(ctx, wishlist.Items); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+func (s *userService) DeleteWishlist(ctx context.Context, userID uint, id uuid.UUID) error {
+	err := s.wishlistRepo.Delete(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrWishlistNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+	return nil
+}
+
+// AddWishlistItem saves a product, or one of its variants, with its current
+// price and stock so that later drops and restocks can be told apart.
+func (s *userService) AddWishlistItem(ctx context.Context, userID uint, wishlistID uuid.UUID, input domain.AddWishlistItemInput) (*domain.WishlistItem, error) {
+	if err := validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wishlist.Items) >= domain.MaxWishlistItems {
+		return nil, domain.ErrWishlistFull
+	}
+
+	exists, err := s.wishlistRepo.HasItem(ctx, wishlistID, input.ProductID, input.VariantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check wishlist: %w", err)
+	}
+	if exists {
+		return nil, domain.ErrAlreadyInWishlist
+	}
+
+	product, err := s.getProduct(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &domain.WishlistItem{
+		WishlistID: wishlistID,
+		ProductID:  input.ProductID,
+		VariantID:  input.VariantID,
+	}
+	priceItem(item, product)
+	if input.VariantID != nil && item.Variant == nil {
+		return nil, fmt.Errorf("%w: variant_id does not belong to the product", validator.ErrValidation)
+	}
+	item.PriceWhenAdded = item.CurrentPrice
+	item.InStockWhenAdded = item.InStock
+
+	if err := s.wishlistRepo.AddItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+	return item, nil
+}
+
+func (s *userService) RemoveWishlistItem(ctx context.Context, userID uint, wishlistID, itemID uuid.UUID) error {
+	if _, err := s.getWishlist(ctx, userID, wishlistID); err != nil {
+		return err
+	}
+
+	err := s.wishlistRepo.RemoveItem(ctx, wishlistID, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrWishlistItemNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) ShareWishlist(ctx context.Context, userID uint, id uuid.UUID) (*domain.Wishlist, error) {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if wishlist.ShareToken == nil {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate share token: %w", err)
+		}
+		token := base64.RawURLEncoding.EncodeToString(raw)
+		wishlist.ShareToken = &token
+
+		if err := s.wishlistRepo.Update(ctx, wishlist); err != nil {
+			return nil, fmt.Errorf("failed to update wishlist: %w", err)
+		}
+	}
+
+	if err := s.priceWishlistItems(ctx, wishlist.Items); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// UnshareWishlist breaks the existing share link; sharing again makes a new
+// one.
+func (s *userService) UnshareWishlist(ctx context.Context, userID uint, id uuid.UUID) error {
+	wishlist, err := s.getWishlist(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if wishlist.ShareToken == nil {
+		return nil
+	}
+
+	wishlist.ShareToken = nil
+	if err := s.wishlistRepo.Update(ctx, wishlist); err != nil {
+		return fmt.Errorf("failed to update wishlist: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) GetSharedWishlist(ctx context.Context, token string) (*domain.SharedWishlist, error) {
+	wishlist, err := s.wishlistRepo.GetByShareToken(ctx, token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+
+	owner, err := s.GetUserByID(ctx, wishlist.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.priceWishlistItems(ctx, wishlist.Items); err != nil {
+		return nil, err
+	}
+	return &domain.SharedWishlist{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		OwnerName: owner.FirstName,
+		UpdatedAt: wishlist.UpdatedAt,
+		Items:     wishlist.Items,
+	}, nil
+}
+
+func (s *userService) AddWishlistItemToCart(ctx context.Context, userID uint, wishlistID, itemID uuid.UUID, input domain.WishlistCartInput) error {
+	if err := validator.ValidateStruct(input); err != nil {
+		return err
+	}
+	if s.cart == nil {
+		return domain.ErrCartUnavailable
+	}
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+
+	wishlist, err := s.getWishlist(ctx, userID, wishlistID)
+	if err != nil {
+		return err
+	}
+	var item *domain.WishlistItem
+	for _, i := range wishlist.Items {
+		if i.ID == itemID {
+			item = i
+			break
+		}
+	}
+	if item == nil {
+		return domain.ErrWishlistItemNotFound
+	}
+
+	variantID := item.VariantID
+	if variantID == nil {
+		variantID = input.VariantID
+	}
+	if variantID == nil {
+		return fmt.Errorf("%w: variant_id is required for an item saved without one", validator.ErrValidation)
+	}
+
+	product, err := s.getProduct(ctx, item.ProductID)
+	if errors.Is(err, domain.ErrProductNotFound) {
+		return domain.ErrProductUnavailable
+	}
+	if err != nil {
+		return err
+	}
+	variant := findVariant(product, *variantID)
+	if variant == nil {
+		return fmt.Errorf("%w: variant_id does not belong to the product", validator.ErrValidation)
+	}
+	if product.Status != "active" || !variant.IsActive {
+		return domain.ErrProductUnavailable
+	}
+	if variant.Stock < input.Quantity {
+		return domain.ErrOutOfStock
+	}
+
+	if err := s.cart.AddItem(ctx, userID, variant.ID, input.Quantity); err != nil {
+		return fmt.Errorf("failed to add to cart: %w", err)
+	}
+	return nil
+}
+
+func (s *userService) getWishlist(ctx context.Context, userID uint, id uuid.UUID) (*domain.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.GetByID(ctx, userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrWishlistNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+	return wishlist, nil
+}
+
+func (s *userService) getProduct(ctx context.Context, id uuid.UUID) (*productdomain.ProductWithVariants, error) {
+	product, err := s.catalog.GetProduct(ctx, id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, domain.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return product, nil
+}
+
+// priceWishlistItems fills in the current catalog data of the items. Items
+// whose product has since been deleted are shown as unavailable.
+func (s *userService) priceWishlistItems(ctx context.Context, items []*domain.WishlistItem) error {
+	products := make(map[uuid.UUID]*productdomain.ProductWithVariants)
+	for _, item := range items {
+		product, seen := products[item.ProductID]
+		if !seen {
+			var err error
+			product, err = s.getProduct(ctx, item.ProductID)
+			if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+				return err
+			}
+			products[item.ProductID] = product
+		}
+		if product != nil {
+			priceItem(item, product)
+		}
+		item.PriceDropped = item.PriceWhenAdded != nil && item.CurrentPrice != nil && *item.CurrentPrice < *item.PriceWhenAdded
+		item.BackInStock = !item.InStockWhenAdded && item.InStock
+	}
+	return nil
+}
+
+// priceItem sets the item's availability, price and stock from the product.
+// An item without a variant takes the lowest price of the active variants
+// and is in stock if any of them is.
+func priceItem(item *domain.WishlistItem, product *productdomain.ProductWithVariants) {
+	item.Product = product.Product
+	item.Available = false
+	item.CurrentPrice = nil
+	item.InStock = false
+
+	if item.VariantID != nil {
+		variant := findVariant(product, *item.VariantID)
+		if variant == nil {
+			return
+		}
+		item.Variant = variant
+		price := effectivePrice(variant)
+		item.CurrentPrice = &price
+		item.Available = product.Status == "active" && variant.IsActive
+		item.InStock = item.Available && variant.Stock > 0
+		return
+	}
+
+	if product.Status != "active" {
+		return
+	}
+	for _, variant := range product.Variants {
+		if !variant.IsActive {
+			continue
+		}
+		item.Available = true
+		price := effectivePrice(variant)
+		if item.CurrentPrice == nil || price < *item.CurrentPrice {
+			item.CurrentPrice = &price
+		}
+		if variant.Stock > 0 {
+			item.InStock = true
+		}
+	}
+}
+
+func findVariant(product *productdomain.ProductWithVariants, id uuid.UUID) *productdomain.ProductVariant {
+	for _, variant := range product.Variants {
+		if variant.ID == id {
+			return variant
+		}
+	}
+	return nil
+}
+
+func effectivePrice(variant *productdomain.ProductVariant) float64 {
+	if variant.DiscountPrice != nil && *variant.DiscountPrice < variant.Price {
+		return *variant.DiscountPrice
+	}
+	return variant.Price
+}
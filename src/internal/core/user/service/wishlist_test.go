@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	productdomain "golang_marketplace/src/internal/core/product/domain"
+	"golang_marketplace/src/internal/core/user/domain"
+	"golang_marketplace/src/internal/platform/cache"
+	"testing"
+)
+
+type stubCatalog struct {
+	products map[uuid.UUID]*productdomain.ProductWithVariants
+	lookups  int
+}
+
+func (c *stubCatalog) GetProduct(_ context.Context, id uuid.UUID) (*productdomain.ProductWithVariants, error) {
+	c.lookups++
+	product, ok := c.products[id]
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return product, nil
+}
+
+func price(v float64) *float64 {
+	return &v
+}
+
+func TestPriceWishlistItems(t *testing.T) {
+	cheap := &productdomain.ProductVariant{ID: uuid.New(), Price: 30, DiscountPrice: price(20), Stock: 0, IsActive: true}
+	dear := &productdomain.ProductVariant{ID: uuid.New(), Price: 50, Stock: 4, IsActive: true}
+	retired := &productdomain.ProductVariant{ID: uuid.New(), Price: 5, Stock: 9, IsActive: false}
+	active := &productdomain.ProductWithVariants{
+		Product:  &productdomain.Product{ID: uuid.New(), Status: "active"},
+		Variants: []*productdomain.ProductVariant{cheap, dear, retired},
+	}
+	draft := &productdomain.ProductWithVariants{
+		Product:  &productdomain.Product{ID: uuid.New(), Status: "draft"},
+		Variants: []*productdomain.ProductVariant{dear},
+	}
+	deleted := uuid.New()
+
+	tests := []struct {
+		name          string
+		item          *domain.WishlistItem
+		wantAvailable bool
+		wantPrice     *float64
+		wantInStock   bool
+		wantDropped   bool
+		wantRestocked bool
+	}{
+		{
+			name:          "variant with a discount that dropped below the saved price",
+			item:          &domain.WishlistItem{ProductID: active.ID, VariantID: &cheap.ID, PriceWhenAdded: price(25)},
+			wantAvailable: true,
+			wantPrice:     price(20),
+			wantDropped:   true,
+		},
+		{
+			name:          "variant back in stock",
+			item:          &domain.WishlistItem{ProductID: active.ID, VariantID: &dear.ID, PriceWhenAdded: price(50)},
+			wantAvailable: true,
+			wantPrice:     price(50),
+			wantInStock:   true,
+			wantRestocked: true,
+		},
+		{
+			name:          "variant in stock when added",
+			item:          &domain.WishlistItem{ProductID: active.ID, VariantID: &dear.ID, InStockWhenAdded: true},
+			wantAvailable: true,
+			wantPrice:     price(50),
+			wantInStock:   true,
+		},
+		{
+			name:      "inactive variant",
+			item:      &domain.WishlistItem{ProductID: active.ID, VariantID: &retired.ID},
+			wantPrice: price(5),
+		},
+		{
+			name:          "no variant takes the lowest active price and any stock",
+			item:          &domain.WishlistItem{ProductID: active.ID, PriceWhenAdded: price(20)},
+			wantAvailable: true,
+			wantPrice:     price(20),
+			wantInStock:   true,
+			wantRestocked: true,
+		},
+		{
+			name: "variant no longer on the product",
+			item: &domain.WishlistItem{ProductID: active.ID, VariantID: func() *uuid.UUID { id := uuid.New(); return &id }()},
+		},
+		{
+			name: "product not active",
+			item: &domain.WishlistItem{ProductID: draft.ID},
+		},
+		{
+			name: "deleted product",
+			item: &domain.WishlistItem{ProductID: deleted, PriceWhenAdded: price(10), InStockWhenAdded: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &stubCatalog{products: map[uuid.UUID]*productdomain.ProductWithVariants{active.ID: active, draft.ID: draft}}
+			s := &userService{catalog: catalog}
+
+			if err := s.priceWishlistItems(context.Background(), []*domain.WishlistItem{tt.item}); err != nil {
+				t.Fatal(err)
+			}
+
+			item := tt.item
+			if item.Available != tt.wantAvailable {
+				t.Errorf("available = %v, want %v", item.Available, tt.wantAvailable)
+			}
+			if (item.CurrentPrice == nil) != (tt.wantPrice == nil) ||
+				(item.CurrentPrice != nil && *item.CurrentPrice != *tt.wantPrice) {
+				t.Errorf("current price = %v, want %v", deref(item.CurrentPrice), deref(tt.wantPrice))
+			}
+			if item.InStock != tt.wantInStock {
+				t.Errorf("in stock = %v, want %v", item.InStock, tt.wantInStock)
+			}
+			if item.PriceDropped != tt.wantDropped {
+				t.Errorf("price dropped = %v, want %v", item.PriceDropped, tt.wantDropped)
+			}
+			if item.BackInStock != tt.wantRestocked {
+				t.Errorf("back in stock = %v, want %v", item.BackInStock, tt.wantRestocked)
+			}
+		})
+	}
+}
+
+func TestPriceWishlistItemsLooksUpEachProductOnce(t *testing.T) {
+	product := &productdomain.ProductWithVariants{Product: &productdomain.Product{ID: uuid.New(), Status: "active"}}
+	deleted := uuid.New()
+	catalog := &stubCatalog{products: map[uuid.UUID]*productdomain.ProductWithVariants{product.ID: product}}
+	s := &userService{catalog: catalog}
+
+	items := []*domain.WishlistItem{
+		{ProductID: product.ID}, {ProductID: product.ID}, {ProductID: deleted}, {ProductID: deleted},
+	}
+	if err := s.priceWishlistItems(context.Background(), items); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.lookups != 2 {
+		t.Errorf("catalog was asked %d times, want 2", catalog.lookups)
+	}
+}
+
+func TestAddWishlistItemToCartWithoutCart(t *testing.T) {
+	// until a cart module registers, the endpoint answers 501
+	s := &userService{}
+	err := s.AddWishlistItemToCart(context.Background(), 1, uuid.New(), uuid.New(), domain.WishlistCartInput{})
+	if !errors.Is(err, domain.ErrCartUnavailable) {
+		t.Errorf("err = %v, want %v", err, domain.ErrCartUnavailable)
+	}
+}
+
+func deref(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
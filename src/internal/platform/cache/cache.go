@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_marketplace/src/configs"
+	"time"
+)
+
+const (
+	DriverRedis  = "redis"
+	DriverMemory = "memory"
+)
+
+var ErrCacheMiss = errors.New("cache: key not found")
+
+// Cache stores JSON-encoded values under string keys. A zero ttl keeps the
+// value until it is deleted or evicted. Values stored with tags can be
+// dropped together by invalidating any one of their tags. Incr treats the
+// value as a counter: it adds one, starting from zero, and sets ttl only when
+// it creates the key, so the window is fixed from the first increment.
+type Cache interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Delete(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+func New(cfg configs.CacheConfig, redisCfg configs.RedisConfig) (Cache, error) {
+	switch cfg.Driver {
+	case DriverRedis, "":
+		return Connect(redisCfg), nil
+	case DriverMemory:
+		return NewMemoryCache(cfg.MaxEntries), nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golang.org/x/sync/singleflight"
+	"sync/atomic"
+	"time"
+)
+
+const refreshTimeout = 10 * time.Second
+
+// ErrNotFound is returned by a fetch function to report that the value does
+// not exist. The loader caches that answer for NegativeTTL and returns
+// ErrNotFound to every caller in the meantime.
+var ErrNotFound = errors.New("cache: value not found")
+
+type LoaderOptions struct {
+	// SoftTTL is how long a value is served as fresh. After it passes the
+	// value is still served, but one caller refreshes it in the background.
+	SoftTTL time.Duration
+	// HardTTL is how long a value is kept at all; past it callers block on
+	// a fresh load.
+	HardTTL time.Duration
+	// NegativeTTL is how long a not-found answer is kept.
+	NegativeTTL time.Duration
+}
+
+// Tagged can be returned by a fetch function to store the value under the
+// given invalidation tags.
+type Tagged struct {
+	Value interface{}
+	Tags  []string
+}
+
+type Stats struct {
+	Hits          uint64 `json:"hits"`
+	StaleHits     uint64 `json:"stale_hits"`
+	Misses        uint64 `json:"misses"`
+	Refreshes     uint64 `json:"refreshes"`
+	RefreshErrors uint64 `json:"refresh_errors"`
+}
+
+// Loader reads through a Cache, coalescing concurrent loads of the same key
+// into a single fetch and serving stale values while they are refreshed.
+type Loader struct {
+	cache Cache
+	opts  LoaderOptions
+	group singleflight.Group
+
+	hits          atomic.Uint64
+	staleHits     atomic.Uint64
+	misses        atomic.Uint64
+	refreshes     atomic.Uint64
+	refreshErrors atomic.Uint64
+}
+
+type envelope struct {
+	Value      json.RawMessage `json:"value,omitempty"`
+	NotFound   bool            `json:"not_found,omitempty"`
+	SoftExpiry time.Time       `json:"soft_expiry"`
+}
+
+func NewLoader(cache Cache, opts LoaderOptions) *Loader {
+	if opts.HardTTL < opts.SoftTTL {
+		opts.HardTTL = opts.SoftTTL
+	}
+
+	return &Loader{
+		cache: cache,
+		opts:  opts,
+	}
+}
+
+// Load decodes the value stored under key into dest, calling fetch to
+// produce it when it is missing or stale.
+func (l *Loader) Load(ctx context.Context, key string, dest interface{}, fetch func(ctx context.Context) (interface{}, error)) error {
+	var env envelope
+	if err := l.cache.Get(ctx, key, &env); err == nil {
+		if time.Now().Before(env.SoftExpiry) {
+			l.hits.Add(1)
+		} else {
+			l.staleHits.Add(1)
+			l.group.DoChan(key, func() (interface{}, error) {
+				return l.refresh(ctx, key, fetch)
+			})
+		}
+		return decode(env, dest)
+	}
+
+	l.misses.Add(1)
+	v, err, _ := l.group.Do(key, func() (interface{}, error) {
+		return l.refresh(ctx, key, fetch)
+	})
+	if err != nil {
+		return err
+	}
+
+	return decode(v.(envelope), dest)
+}
+
+func (l *Loader) Stats() Stats {
+	return Stats{
+		Hits:          l.hits.Load(),
+		StaleHits:     l.staleHits.Load(),
+		Misses:        l.misses.Load(),
+		Refreshes:     l.refreshes.Load(),
+		RefreshErrors: l.refreshErrors.Load(),
+	}
+}
+
+// refresh runs fetch and stores the result. It is shared by every waiter on
+// the key, so it must not be bound to the first caller's cancellation.
+func (l *Loader) refresh(ctx context.Context, key string, fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+	defer cancel()
+
+	l.refreshes.Add(1)
+
+	value, err := fetch(ctx)
+	if errors.Is(err, ErrNotFound) {
+		env := envelope{NotFound: true, SoftExpiry: time.Now().Add(l.opts.NegativeTTL)}
+		if l.opts.NegativeTTL > 0 {
+			_ = l.cache.Set(ctx, key, env, l.opts.NegativeTTL)
+		}
+		return env, nil
+	}
+	if err != nil {
+		l.refreshErrors.Add(1)
+		return nil, err
+	}
+
+	var tags []string
+	if tagged, ok := value.(Tagged); ok {
+		value, tags = tagged.Value, tagged.Tags
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		l.refreshErrors.Add(1)
+		return nil, err
+	}
+
+	env := envelope{Value: raw, SoftExpiry: time.Now().Add(l.opts.SoftTTL)}
+	_ = l.cache.SetWithTags(ctx, key, env, l.opts.HardTTL, tags...)
+
+	return env, nil
+}
+
+func decode(env envelope, dest interface{}) error {
+	if env.NotFound {
+		return ErrNotFound
+	}
+	return json.Unmarshal(env.Value, dest)
+}
@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxEntries = 10000
+	// minPinnedSweep is the number of pinned entries below which expired
+	// ones are left for lookups to drop.
+	minPinnedSweep = 1024
+)
+
+// MemoryCache is an in-process LRU cache with per-key expiry. Values are
+// stored JSON-encoded so callers get the same copy semantics as with Redis.
+// Keys under a pinned prefix are kept outside the LRU: they never count
+// towards maxEntries and only go once they expire or are deleted.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	pinned     *list.List
+	prefixes   []string
+	sweepAt    int
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{}
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	tags      []string
+	pinned    bool
+}
+
+// Pinner is implemented by caches that evict entries to stay within a
+// size limit. Keys under a pinned prefix are never evicted.
+type Pinner interface {
+	Pin(prefixes ...string)
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		pinned:     list.New(),
+		sweepAt:    minPinnedSweep,
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// Pin keeps keys starting with any of prefixes out of the LRU. It applies
+// to entries written from then on.
+func (c *MemoryCache) Pin(prefixes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prefixes = append(c.prefixes, prefixes...)
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.SetWithTags(ctx, key, value, ttl)
+}
+
+func (c *MemoryCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	c.insert(&memoryEntry{
+		key:       key,
+		value:     jsonValue,
+		expiresAt: expiresAt,
+		tags:      tags,
+	})
+	return nil
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.lookup(key)
+	var value []byte
+	if ok {
+		value = entry.value
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(value, dest)
+}
+
+func (c *MemoryCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.lookup(key); ok {
+		var n int64
+		if err := json.Unmarshal(entry.value, &n); err != nil {
+			return 0, err
+		}
+		n++
+		entry.value = []byte(strconv.FormatInt(n, 10))
+		return n, nil
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	c.insert(&memoryEntry{
+		key:       key,
+		value:     []byte("1"),
+		expiresAt: expiresAt,
+	})
+	return 1, nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+	return nil
+}
+
+func (c *MemoryCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if el, ok := c.items[key]; ok {
+				c.removeElement(el)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.lookup(key)
+	return ok, nil
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *MemoryCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.pinned.Init()
+	c.items = make(map[string]*list.Element)
+	c.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// lookup returns the live entry for key and marks it as recently used,
+// dropping it instead if it has expired. The caller must hold c.mu.
+func (c *MemoryCache) lookup(key string) (*memoryEntry, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+
+	if !entry.pinned {
+		c.ll.MoveToFront(el)
+	}
+	return entry, true
+}
+
+// insert adds entry, which must not be in the cache yet, and evicts the
+// least recently used entries over maxEntries. The caller must hold c.mu.
+func (c *MemoryCache) insert(entry *memoryEntry) {
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(entry.key, prefix) {
+			entry.pinned = true
+			break
+		}
+	}
+
+	if entry.pinned {
+		c.items[entry.key] = c.pinned.PushBack(entry)
+		if c.pinned.Len() >= c.sweepAt {
+			c.sweepPinned()
+		}
+	} else {
+		c.items[entry.key] = c.ll.PushFront(entry)
+	}
+
+	for _, tag := range entry.tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[entry.key] = struct{}{}
+	}
+
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// sweepPinned drops expired pinned entries, which no eviction would, and
+// schedules the next sweep for when their number has doubled. The caller
+// must hold c.mu.
+func (c *MemoryCache) sweepPinned() {
+	now := time.Now()
+	for el := c.pinned.Front(); el != nil; {
+		next := el.Next()
+		entry := el.Value.(*memoryEntry)
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			c.removeElement(el)
+		}
+		el = next
+	}
+	c.sweepAt = max(2*c.pinned.Len(), minPinnedSweep)
+}
+
+func (c *MemoryCache) removeElement(el *list.Element) {
+	entry := el.Value.(*memoryEntry)
+	if entry.pinned {
+		c.pinned.Remove(el)
+	} else {
+		c.ll.Remove(el)
+	}
+	delete(c.items, entry.key)
+
+	for _, tag := range entry.tags {
+		if keys, ok := c.tags[tag]; ok {
+			delete(keys, entry.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ops      func(c *MemoryCache)
+		wantKept []string
+		wantGone []string
+	}{
+		{
+			name: "oldest write goes first",
+			ops: func(c *MemoryCache) {
+				_ = c.Set(ctx, "a", 1, 0)
+				_ = c.Set(ctx, "b", 2, 0)
+				_ = c.Set(ctx, "c", 3, 0)
+			},
+			wantKept: []string{"b", "c"},
+			wantGone: []string{"a"},
+		},
+		{
+			name: "a read keeps an entry",
+			ops: func(c *MemoryCache) {
+				_ = c.Set(ctx, "a", 1, 0)
+				_ = c.Set(ctx, "b", 2, 0)
+				var v int
+				_ = c.Get(ctx, "a", &v)
+				_ = c.Set(ctx, "c", 3, 0)
+			},
+			wantKept: []string{"a", "c"},
+			wantGone: []string{"b"},
+		},
+		{
+			name: "overwriting does not count twice",
+			ops: func(c *MemoryCache) {
+				_ = c.Set(ctx, "a", 1, 0)
+				_ = c.Set(ctx, "a", 2, 0)
+				_ = c.Set(ctx, "b", 3, 0)
+			},
+			wantKept: []string{"a", "b"},
+		},
+		{
+			name: "pinned keys are never evicted",
+			ops: func(c *MemoryCache) {
+				_ = c.Set(ctx, "auth:denied:1", true, time.Hour)
+				_, _ = c.Incr(ctx, "auth:login_failures:1", time.Hour)
+				_ = c.Set(ctx, "a", 1, 0)
+				_ = c.Set(ctx, "b", 2, 0)
+				_ = c.Set(ctx, "c", 3, 0)
+			},
+			wantKept: []string{"auth:denied:1", "auth:login_failures:1", "b", "c"},
+			wantGone: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemoryCache(2)
+			c.Pin("auth:")
+			tt.ops(c)
+			for _, key := range tt.wantKept {
+				if ok, _ := c.Exists(ctx, key); !ok {
+					t.Errorf("%s was evicted", key)
+				}
+			}
+			for _, key := range tt.wantGone {
+				if ok, _ := c.Exists(ctx, key); ok {
+					t.Errorf("%s was kept", key)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	if err := c.Set(ctx, "short", "v", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "forever", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	var v string
+	if err := c.Get(ctx, "short", &v); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("expired entry: err = %v, want %v", err, ErrCacheMiss)
+	}
+	if err := c.Get(ctx, "forever", &v); err != nil || v != "v" {
+		t.Errorf("entry without ttl: %q, %v", v, err)
+	}
+}
+
+func TestMemoryCacheInvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	_ = c.SetWithTags(ctx, "page1", 1, 0, "category:a", "products")
+	_ = c.SetWithTags(ctx, "page2", 2, 0, "category:b")
+	_ = c.SetWithTags(ctx, "page3", 3, 0, "products")
+
+	if err := c.InvalidateTags(ctx, "category:a"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"page1": false, "page2": true, "page3": true} {
+		if ok, _ := c.Exists(ctx, key); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+
+	// a key rewritten without a tag is no longer invalidated by it
+	_ = c.Set(ctx, "page3", 3, 0)
+	_ = c.InvalidateTags(ctx, "products")
+	if ok, _ := c.Exists(ctx, "page3"); !ok {
+		t.Error("page3 was invalidated by a tag it no longer has")
+	}
+}
+
+func TestMemoryCacheIncr(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := c.Incr(ctx, "attempts", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Incr = %d, want %d", got, want)
+		}
+	}
+
+	var stored int64
+	if err := c.Get(ctx, "attempts", &stored); err != nil || stored != 3 {
+		t.Errorf("Get = %d, %v, want 3", stored, err)
+	}
+
+	// counters written with Set, as a spent challenge is, keep counting
+	if err := c.Set(ctx, "spent", 5, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Incr(ctx, "spent", time.Hour); err != nil || got != 6 {
+		t.Errorf("Incr after Set = %d, %v, want 6", got, err)
+	}
+
+	if err := c.Set(ctx, "text", "abc", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Incr(ctx, "text", time.Hour); err == nil {
+		t.Error("Incr of a non-number succeeded")
+	}
+}
+
+func TestMemoryCacheIncrKeepsItsWindow(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	if _, err := c.Incr(ctx, "window", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	// a later increment does not push the expiry back
+	if _, err := c.Incr(ctx, "window", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(15 * time.Millisecond)
+
+	got, err := c.Incr(ctx, "window", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("Incr after the window = %d, want a fresh count of 1", got)
+	}
+}
+
+func TestMemoryCacheSweepsPinnedKeys(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+	c.Pin("auth:")
+
+	for i := 0; i < minPinnedSweep-1; i++ {
+		_ = c.Set(ctx, fmt.Sprintf("auth:denied:%d", i), true, time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	_ = c.Set(ctx, "auth:denied:kept", true, time.Hour)
+
+	if n := c.pinned.Len(); n != 1 {
+		t.Errorf("pinned entries after a sweep = %d, want 1", n)
+	}
+	if ok, _ := c.Exists(ctx, "auth:denied:kept"); !ok {
+		t.Error("live pinned entry was swept")
+	}
+}
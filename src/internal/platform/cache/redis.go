@@ -1,56 +1,186 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/go-redis/redis"
+	"golang_marketplace/src/configs"
 	"time"
 )
 
-type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-}
+// setWithTagsScript stores the value and registers its key in every tag set
+// atomically. A tag set lives at least as long as the longest-lived key in it.
+var setWithTagsScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call('PERSIST', KEYS[i])
+	else
+		local current = redis.call('PTTL', KEYS[i])
+		if existed == 0 or (current >= 0 and current < ttl) then
+			redis.call('PEXPIRE', KEYS[i], ttl)
+		end
+	end
+end
+return 1
+`)
+
+var invalidateTagsScript = redis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	local keys = redis.call('SMEMBERS', tag)
+	for i = 1, #keys, 500 do
+		redis.call('DEL', unpack(keys, i, math.min(i + 499, #keys)))
+	end
+	redis.call('DEL', tag)
+end
+return 1
+`)
+
+// incrScript increments a counter and starts its expiry on creation only.
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+local ttl = tonumber(ARGV[1])
+if n == 1 and ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return n
+`)
 
-type Cache struct {
+// pushRecentScript scores the member by time in a sorted set, so that it
+// sorts as the newest, and drops all but the newest max members.
+var pushRecentScript = redis.NewScript(`
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -(tonumber(ARGV[3]) + 1))
+local ttl = tonumber(ARGV[4])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+type RedisCache struct {
 	client *redis.Client
 }
 
-func Connect(cfg RedisConfig) *Cache {
+func Connect(cfg configs.RedisConfig) *RedisCache {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.DB,
 	})
 
-	return &Cache{
+	return &RedisCache{
 		client: rdb,
 	}
 }
 
-func (c *Cache) Set(key string, value interface{}, expiration time.Duration) error {
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	jsonValue, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(key, jsonValue, expiration).Err()
+	return c.client.WithContext(ctx).Set(key, jsonValue, ttl).Err()
 }
 
-func (c *Cache) Get(key string, dest interface{}) error {
-	val, err := c.client.Get(key).Result()
+func (c *RedisCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return c.Set(ctx, key, value, ttl)
+	}
+
+	jsonValue, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal([]byte(val), dest)
+
+	keys := append([]string{key}, tagKeys(tags)...)
+	return setWithTagsScript.Run(c.client.WithContext(ctx), keys, jsonValue, ttl.Milliseconds()).Err()
+}
+
+func (c *RedisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return invalidateTagsScript.Run(c.client.WithContext(ctx), tagKeys(tags)).Err()
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string, dest interface{}) error {
+	val, err := c.client.WithContext(ctx).Get(key).Bytes()
+	if err == redis.Nil {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(val, dest)
+}
+
+func (c *RedisCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(c.client.WithContext(ctx), []string{key}, ttl.Milliseconds()).Int64()
+}
+
+// PushRecent, Recent and RemoveRecent keep a recency list under a key in a
+// sorted set: up to max distinct members, newest first. Pushing a member
+// already in the list moves it to the front, and every push restarts ttl.
+// They are not part of Cache; modules that need recency lists declare their
+// own interface.
+func (c *RedisCache) PushRecent(ctx context.Context, key, member string, max int, ttl time.Duration) error {
+	score := time.Now().UnixMicro()
+	return pushRecentScript.Run(c.client.WithContext(ctx), []string{key}, member, score, max, ttl.Milliseconds()).Err()
+}
+
+func (c *RedisCache) Recent(ctx context.Context, key string, limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	return c.client.WithContext(ctx).ZRevRange(key, 0, int64(limit-1)).Result()
 }
 
-func (c *Cache) Delete(key string) error {
-	return c.client.Del(key).Err()
+func (c *RedisCache) RemoveRecent(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return c.client.WithContext(ctx).ZRem(key, values...).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.WithContext(ctx).Del(keys...).Err()
 }
 
-func (c *Cache) Exists(key string) bool {
-	result := c.client.Exists(key)
-	return result.Val() > 0
+func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.WithContext(ctx).Exists(key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.WithContext(ctx).Ping().Err()
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = "tag:" + tag
+	}
+	return keys
 }
@@ -1,33 +1,114 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"golang_marketplace/src/configs"
+	"golang_marketplace/src/internal/platform/logging"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+	"log/slog"
+	"strings"
 )
 
-type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
 }
 
-func Connect(cfg DatabaseConfig) (*gorm.DB, error) {
+// Connect opens the primary database and, when replica DSNs are configured,
+// registers them so that reads go to a replica and writes to the primary.
+// A read that must see its own writes can be pinned to the primary with
+// Clauses(dbresolver.Write).
+func Connect(cfg configs.DatabaseConfig, log *slog.Logger) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode,
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(postgres.Open(withStatementTimeout(dsn, cfg)), &gorm.Config{
+		Logger:         logging.NewGormLogger(log, logLevels[cfg.LogLevel], cfg.SlowQueryThreshold),
+		TranslateError: true,
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database handle: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if len(cfg.ReplicaDSNs) > 0 {
+		replicas := make([]gorm.Dialector, 0, len(cfg.ReplicaDSNs))
+		for _, replicaDSN := range cfg.ReplicaDSNs {
+			replicas = append(replicas, postgres.Open(withStatementTimeout(replicaDSN, cfg)))
+		}
+
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		}).
+			SetMaxOpenConns(cfg.MaxOpenConns).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetConnMaxLifetime(cfg.ConnMaxLifetime).
+			SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+		if err := db.Use(resolver); err != nil {
+			return nil, fmt.Errorf("failed to register read replicas: %w", err)
+		}
+	}
+
 	return db, nil
 }
+
+// withStatementTimeout makes the server cancel any single statement that
+// runs longer than the configured timeout.
+func withStatementTimeout(dsn string, cfg configs.DatabaseConfig) string {
+	if cfg.StatementTimeout <= 0 {
+		return dsn
+	}
+
+	param := fmt.Sprintf("statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	if !strings.Contains(dsn, "://") {
+		return dsn + " " + param
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + param
+	}
+	return dsn + "?" + param
+}
+
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the primary pool and every replica pool.
+func Close(db *gorm.DB) error {
+	if resolver, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()].(*dbresolver.DBResolver); ok {
+		return resolver.Call(func(connPool gorm.ConnPool) error {
+			if closer, ok := connPool.(interface{ Close() error }); ok {
+				return closer.Close()
+			}
+			return nil
+		})
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+	return sqlDB.Close()
+}
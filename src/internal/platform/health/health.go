@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const checkTimeout = 2 * time.Second
+
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Handler serves liveness and readiness probes. Readiness runs every
+// registered dependency check in parallel and fails while the server is
+// draining, so the orchestrator stops routing traffic before shutdown.
+type Handler struct {
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		checks: make(map[string]Check),
+	}
+}
+
+// AddCheck registers a dependency check. It must be called before the
+// handler starts serving.
+func (h *Handler) AddCheck(name string, check Check) {
+	h.checks[name] = check
+}
+
+func (h *Handler) SetDraining() {
+	h.draining.Store(true)
+}
+
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/healthz", h.Liveness)
+	router.GET("/readyz", h.Readiness)
+}
+
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{Status: "ok"})
+}
+
+func (h *Handler) Readiness(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, Response{Status: "draining"})
+		return
+	}
+
+	results := h.runChecks(c.Request.Context())
+
+	status, code := "ok", http.StatusOK
+	for _, result := range results {
+		if result.Status != "up" {
+			status, code = "unavailable", http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	c.JSON(code, Response{Status: status, Checks: results})
+}
+
+func (h *Handler) runChecks(ctx context.Context) map[string]CheckResult {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]CheckResult, len(h.checks))
+
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := CheckResult{Status: "up", Latency: time.Since(start).String()}
+			if err != nil {
+				result.Status = "down"
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+	return results
+}
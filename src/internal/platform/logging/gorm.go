@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log/slog"
+	"time"
+)
+
+// GormLogger sends GORM's logs through slog so SQL shares the format, level
+// and request attributes of the rest of the service. Failed statements are
+// logged as errors, slow ones as warnings and the rest at debug.
+type GormLogger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(l *slog.Logger, level logger.LogLevel, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		logger:        l,
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (g *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *g
+	clone.level = level
+	return &clone
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Info {
+		g.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Warn {
+		g.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= logger.Error {
+		g.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && g.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		g.logger.ErrorContext(ctx, "query failed",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+			slog.Any("error", err),
+		)
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold && g.level >= logger.Warn:
+		sql, rows := fc()
+		g.logger.WarnContext(ctx, "slow query",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+			slog.Duration("threshold", g.slowThreshold),
+		)
+	case g.level >= logger.Info:
+		sql, rows := fc()
+		g.logger.DebugContext(ctx, "query",
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+		)
+	}
+}
@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"golang_marketplace/src/migrations"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func file(content string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(content)}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_add_index.up.sql":        file("CREATE INDEX"),
+		"10_add_index.down.sql":      file("DROP INDEX"),
+		"9_create_table.up.sql":      file("\xEF\xBB\xBFCREATE TABLE"),
+		"9_create_table.down.sql":    file("DROP TABLE"),
+		"002_seed.up.sql":            file("INSERT"),
+		"002_seed.down.sql":          file("DELETE"),
+		"README.md":                  file("not a migration"),
+		"003_notes.sql":              file("not a migration either"),
+		"nested/004_nested.up.sql":   file("ignored"),
+		"nested/004_nested.down.sql": file("ignored"),
+	}
+
+	got, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 2, Name: "seed", Up: "INSERT", Down: "DELETE"},
+		{Version: 9, Name: "create_table", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsBrokenSets(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"001_a.up.sql": file("CREATE"),
+			},
+			wantErr: "must have both up and down files",
+		},
+		{
+			name: "missing up",
+			fsys: fstest.MapFS{
+				"001_a.down.sql": file("DROP"),
+			},
+			wantErr: "must have both up and down files",
+		},
+		{
+			name: "version used twice",
+			fsys: fstest.MapFS{
+				"001_a.up.sql":   file("CREATE"),
+				"001_a.down.sql": file("DROP"),
+				"001_b.up.sql":   file("CREATE"),
+				"001_b.down.sql": file("DROP"),
+			},
+			wantErr: "is used by both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestShippedMigrations guards the migrations in the repository: they must
+// load and be numbered 1, 2, 3... without gaps, so that a migration added on
+// two branches at once is noticed.
+func TestShippedMigrations(t *testing.T) {
+	loaded, err := load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations found")
+	}
+	for i, m := range loaded {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s is at position %d", m.Version, m.Name, i+1)
+		}
+	}
+}
@@ -1,4 +1,6 @@
-﻿CREATE TABLE categories
+-- IF NOT EXISTS lets this baseline adopt databases created by the old docker-entrypoint-initdb.d mount
+
+CREATE TABLE IF NOT EXISTS categories
 (
     id         UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
     name       VARCHAR(255) NOT NULL,
@@ -8,7 +10,7 @@
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
-CREATE TABLE sellers
+CREATE TABLE IF NOT EXISTS sellers
 (
     id           UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
     company_name VARCHAR(255)        NOT NULL,
@@ -21,7 +23,7 @@ CREATE TABLE sellers
     updated_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
-CREATE TABLE products
+CREATE TABLE IF NOT EXISTS products
 (
     id          UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
     name        VARCHAR(255)        NOT NULL,
@@ -36,7 +38,7 @@ CREATE TABLE products
     deleted_at  TIMESTAMP WITH TIME ZONE
 );
 
-CREATE TABLE product_variants
+CREATE TABLE IF NOT EXISTS product_variants
 (
     id             UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
     product_id     UUID           NOT NULL REFERENCES products (id) ON DELETE CASCADE,
@@ -50,17 +52,17 @@ CREATE TABLE product_variants
     updated_at     TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
-CREATE INDEX idx_products_category_id ON products (category_id);
-CREATE INDEX idx_products_sku ON products (sku);
-CREATE INDEX idx_products_status ON products (status);
-CREATE INDEX idx_products_name ON products (name);
-CREATE INDEX idx_products_deleted_at ON products (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
+CREATE INDEX IF NOT EXISTS idx_products_sku ON products (sku);
+CREATE INDEX IF NOT EXISTS idx_products_status ON products (status);
+CREATE INDEX IF NOT EXISTS idx_products_name ON products (name);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
 
-CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
-CREATE INDEX idx_product_variants_seller_id ON product_variants (seller_id);
-CREATE INDEX idx_product_variants_price ON product_variants (price);
-CREATE INDEX idx_product_variants_stock ON product_variants (stock);
-CREATE INDEX idx_product_variants_is_active ON product_variants (is_active);
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
+CREATE INDEX IF NOT EXISTS idx_product_variants_seller_id ON product_variants (seller_id);
+CREATE INDEX IF NOT EXISTS idx_product_variants_price ON product_variants (price);
+CREATE INDEX IF NOT EXISTS idx_product_variants_stock ON product_variants (stock);
+CREATE INDEX IF NOT EXISTS idx_product_variants_is_active ON product_variants (is_active);
 
-CREATE INDEX idx_categories_parent_id ON categories (parent_id);
-CREATE INDEX idx_categories_is_active ON categories (is_active);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_is_active ON categories (is_active);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id            BIGSERIAL PRIMARY KEY,
+    email         VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255)        NOT NULL,
+    first_name    VARCHAR(100),
+    last_name     VARCHAR(100),
+    role          VARCHAR(20)         NOT NULL DEFAULT 'customer',
+    created_at    TIMESTAMP WITH TIME ZONE     DEFAULT NOW(),
+    updated_at    TIMESTAMP WITH TIME ZONE     DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS token_families
+(
+    id             UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    user_id        BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    revoked_at     TIMESTAMP WITH TIME ZONE,
+    revoked_reason VARCHAR(50),
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens
+(
+    id         UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    family_id  UUID                     NOT NULL REFERENCES token_families (id) ON DELETE CASCADE,
+    user_id    BIGINT                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE       NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_families_user_id ON token_families (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
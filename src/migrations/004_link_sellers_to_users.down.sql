@@ -0,0 +1,2 @@
+ALTER TABLE sellers
+    DROP COLUMN IF EXISTS user_id;
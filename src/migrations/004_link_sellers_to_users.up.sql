@@ -0,0 +1,3 @@
+-- lets a seller account act on the variants it owns
+ALTER TABLE sellers
+    ADD COLUMN IF NOT EXISTS user_id BIGINT UNIQUE REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    user_id      BIGINT             NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100)       NOT NULL,
+    prefix       VARCHAR(16)        NOT NULL,
+    key_hash     VARCHAR(64) UNIQUE NOT NULL,
+    scopes       TEXT               NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at   TIMESTAMP WITH TIME ZONE,
+    revoked_at   TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
@@ -0,0 +1,16 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- single-use tokens mailed to users for email verification and password reset
+CREATE TABLE IF NOT EXISTS user_tokens
+(
+    id         UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    user_id    BIGINT                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    VARCHAR(30)              NOT NULL,
+    token_hash VARCHAR(64) UNIQUE       NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id_purpose ON user_tokens (user_id, purpose);
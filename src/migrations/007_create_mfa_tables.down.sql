@@ -0,0 +1,6 @@
+ALTER TABLE token_families
+    DROP COLUMN IF EXISTS mfa_verified;
+
+DROP TABLE IF EXISTS mfa_role_policies;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS mfa_enrollments;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS mfa_enrollments
+(
+    user_id        BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         VARCHAR(64) NOT NULL,
+    enabled_at     TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT      NOT NULL    DEFAULT 0,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at     TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes
+(
+    id         UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    user_id    BIGINT             NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) UNIQUE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- roles whose members must use two-factor authentication
+CREATE TABLE IF NOT EXISTS mfa_role_policies
+(
+    role       VARCHAR(20) PRIMARY KEY,
+    required   BOOLEAN NOT NULL         DEFAULT false,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- whether the login that started a session passed a second factor
+ALTER TABLE token_families
+    ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
@@ -0,0 +1,18 @@
+-- login lockouts, kept for security review
+CREATE TABLE IF NOT EXISTS lockout_events
+(
+    id           UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    scope        VARCHAR(20)              NOT NULL,
+    user_id      BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    email        VARCHAR(255),
+    ip           VARCHAR(45),
+    attempts     INTEGER                  NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    unlocked_at  TIMESTAMP WITH TIME ZONE,
+    unlocked_by  BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lockout_events_user_id ON lockout_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_lockout_events_email ON lockout_events (email);
+CREATE INDEX IF NOT EXISTS idx_lockout_events_created_at ON lockout_events (created_at);
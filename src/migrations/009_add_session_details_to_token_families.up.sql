@@ -0,0 +1,9 @@
+-- each token family is one login session; keep where it was used from
+ALTER TABLE token_families
+    ADD COLUMN IF NOT EXISTS user_agent   VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS ip           VARCHAR(45),
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE token_families
+SET last_used_at = created_at
+WHERE last_used_at IS NULL;
@@ -0,0 +1,33 @@
+-- append-only audit log of authentication; user_id has no foreign key so
+-- that events outlive the account they describe
+CREATE TABLE IF NOT EXISTS auth_events
+(
+    id         UUID PRIMARY KEY         DEFAULT gen_random_uuid(),
+    type       VARCHAR(50) NOT NULL,
+    user_id    BIGINT,
+    actor_id   BIGINT,
+    email      VARCHAR(255),
+    ip         VARCHAR(45),
+    user_agent VARCHAR(512),
+    session_id UUID,
+    details    JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_events_user_id_created_at ON auth_events (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_events_type_created_at ON auth_events (type, created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_events_created_at_id ON auth_events (created_at, id);
+
+CREATE OR REPLACE FUNCTION auth_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'auth_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS auth_events_append_only ON auth_events;
+CREATE TRIGGER auth_events_append_only
+    BEFORE UPDATE OR DELETE
+    ON auth_events
+    FOR EACH ROW
+EXECUTE FUNCTION auth_events_append_only();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS seller_profiles;
+DROP TABLE IF EXISTS customer_profiles;
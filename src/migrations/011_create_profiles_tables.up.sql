@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS customer_profiles
+(
+    user_id            BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    phone              VARCHAR(20),
+    date_of_birth      DATE,
+    preferred_language VARCHAR(35),
+    marketing_opt_in   BOOLEAN NOT NULL         DEFAULT false,
+    created_at         TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at         TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- the seller side of a user; the seller itself is found through
+-- sellers.user_id
+CREATE TABLE IF NOT EXISTS seller_profiles
+(
+    user_id       BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    display_name  VARCHAR(100),
+    bio           TEXT,
+    website       VARCHAR(255),
+    support_email VARCHAR(255),
+    support_phone VARCHAR(20),
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
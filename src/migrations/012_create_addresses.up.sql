@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS addresses
+(
+    id                  UUID PRIMARY KEY                  DEFAULT gen_random_uuid(),
+    user_id             BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    label               VARCHAR(50),
+    full_name           VARCHAR(200) NOT NULL,
+    phone               VARCHAR(20),
+    country             CHAR(2)      NOT NULL,
+    city                VARCHAR(100) NOT NULL,
+    district            VARCHAR(100),
+    postal_code         VARCHAR(20),
+    line1               VARCHAR(255) NOT NULL,
+    line2               VARCHAR(255),
+    is_default_shipping BOOLEAN      NOT NULL             DEFAULT false,
+    is_default_billing  BOOLEAN      NOT NULL             DEFAULT false,
+    created_at          TIMESTAMP WITH TIME ZONE          DEFAULT NOW(),
+    updated_at          TIMESTAMP WITH TIME ZONE          DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_user_id ON addresses (user_id);
+
+-- at most one default of each kind per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_default_shipping ON addresses (user_id) WHERE is_default_shipping;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_default_billing ON addresses (user_id) WHERE is_default_billing;
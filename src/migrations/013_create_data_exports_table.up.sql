@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS data_exports
+(
+    id           UUID PRIMARY KEY                  DEFAULT gen_random_uuid(),
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL              DEFAULT 'pending',
+    size         BIGINT      NOT NULL              DEFAULT 0,
+    error        TEXT,
+    created_at   TIMESTAMP WITH TIME ZONE          DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports (expires_at) WHERE status = 'ready';
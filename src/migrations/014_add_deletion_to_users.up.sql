@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_requested_at  TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS anonymized_at          TIMESTAMP WITH TIME ZONE;
+
+-- the sweeper only looks at accounts waiting to be anonymized
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_for ON users (deletion_scheduled_for)
+    WHERE deletion_scheduled_for IS NOT NULL AND anonymized_at IS NULL;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS wishlists
+(
+    id          UUID PRIMARY KEY                  DEFAULT gen_random_uuid(),
+    user_id     BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    share_token VARCHAR(32) UNIQUE,
+    created_at  TIMESTAMP WITH TIME ZONE          DEFAULT NOW(),
+    updated_at  TIMESTAMP WITH TIME ZONE          DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wishlists_user_id ON wishlists (user_id);
+
+-- deleting a product or variant drops it from every wishlist
+CREATE TABLE IF NOT EXISTS wishlist_items
+(
+    id                  UUID PRIMARY KEY                  DEFAULT gen_random_uuid(),
+    wishlist_id         UUID    NOT NULL REFERENCES wishlists (id) ON DELETE CASCADE,
+    product_id          UUID    NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    variant_id          UUID REFERENCES product_variants (id) ON DELETE CASCADE,
+    price_when_added    DECIMAL(10, 2),
+    in_stock_when_added BOOLEAN NOT NULL                  DEFAULT false,
+    created_at          TIMESTAMP WITH TIME ZONE          DEFAULT NOW()
+);
+
+-- a product is saved at most once per wishlist, with or without a variant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wishlist_items_unique
+    ON wishlist_items (wishlist_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'));
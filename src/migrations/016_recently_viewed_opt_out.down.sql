@@ -0,0 +1 @@
+ALTER TABLE customer_profiles DROP COLUMN IF EXISTS recently_viewed_opt_out;
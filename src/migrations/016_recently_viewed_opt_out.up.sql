@@ -0,0 +1 @@
+ALTER TABLE customer_profiles ADD COLUMN IF NOT EXISTS recently_viewed_opt_out BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id         UUID PRIMARY KEY                  DEFAULT gen_random_uuid(),
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(50)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL             DEFAULT '',
+    link       TEXT         NOT NULL             DEFAULT '',
+    data       JSONB,
+    read_at    TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE          DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, created_at);
+-- keeps the unread count cheap however long the inbox gets
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- a missing row means the channel is on for that type
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    channel    VARCHAR(30) NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_type, channel)
+);
+
+CREATE TABLE IF NOT EXISTS notification_webhooks
+(
+    user_id    BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    url        TEXT        NOT NULL,
+    secret     VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
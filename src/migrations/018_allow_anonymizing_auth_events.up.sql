@@ -0,0 +1,14 @@
+-- auth_events stays append-only, except that the personal columns of an
+-- event may be cleared when its user's account is anonymized
+CREATE OR REPLACE FUNCTION auth_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND (NEW.id, NEW.type, NEW.user_id, NEW.actor_id, NEW.session_id, NEW.details, NEW.created_at)
+            IS NOT DISTINCT FROM
+            (OLD.id, OLD.type, OLD.user_id, OLD.actor_id, OLD.session_id, OLD.details, OLD.created_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'auth_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,83 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890",
+// base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes; these are their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, tt := range rfcVectors {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.code {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted an invalid secret")
+	}
+}
+
+func TestCodeAcceptsLowerCaseSecret(t *testing.T) {
+	got, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "287082" {
+		t.Errorf("Code = %s, want 287082", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int64
+		want     bool
+		wantStep int64
+	}{
+		{name: "current step", code: "050471", skew: 1, want: true, wantStep: current},
+		{name: "surrounding spaces", code: " 050471 ", skew: 1, want: true, wantStep: current},
+		{name: "previous step within skew", code: "081804", skew: 1, want: true, wantStep: current - 1},
+		{name: "previous step without skew", code: "081804", skew: 0},
+		{name: "wrong code", code: "123456", skew: 1},
+		{name: "too short", code: "05047", skew: 1},
+		{name: "eight digit code", code: "14050471", skew: 1},
+		{name: "empty", code: "", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.want {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.code, ok, tt.want)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,6 +10,9 @@ import (
 
 var validate *validator.Validate
 
+// ErrValidation is wrapped by every error returned from ValidateStruct.
+var ErrValidation = errors.New("validation error")
+
 func init() {
 	validate = validator.New()
 }
@@ -20,7 +24,7 @@ func ValidateStruct(s interface{}) error {
 			errors = append(errors, fmt.Sprintf("%s is %s", err.Field(), err.Tag()))
 		}
 
-		return fmt.Errorf("validation error: %s", strings.Join(errors, ", "))
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(errors, ", "))
 	}
 	return nil
 }